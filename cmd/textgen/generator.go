@@ -0,0 +1,510 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	gotypes "go/types"
+	"reflect"
+	"sort"
+
+	pkgerrors "github.com/pkg/errors"
+	encodingx "github.com/utilsgo/x/encoding"
+	"github.com/utilsgo/x/types"
+)
+
+// Generator generates text methods for named types of package
+type Generator struct {
+	pkg *gotypes.Package
+
+	// generated named types
+	named map[*gotypes.Named]bool
+	// reasons of skipped types
+	skipped []string
+
+	buf         *bytes.Buffer
+	useStrconv  bool
+	useEncoding bool
+	useReflect  bool
+}
+
+// NewGenerator creates Generator of pkg
+func NewGenerator(pkg *gotypes.Package) *Generator {
+	return &Generator{
+		pkg:   pkg,
+		named: map[*gotypes.Named]bool{},
+		buf:   bytes.NewBuffer(nil),
+	}
+}
+
+// Generate returns formatted source of AppendText, MarshalText and UnmarshalText methods
+// for named scalar types and structs of package, which encode same text as DefaultTextCodec.
+// Generic types, aliases and types which already have any of these methods are skipped.
+// Types embedded by structs of package, and structs which could not be converted as text
+// are skipped too, and reported by Skipped.
+func (g *Generator) Generate() ([]byte, error) {
+	list := make([]*gotypes.Named, 0)
+	embedded := g.embeddedTypes()
+
+	scope := g.pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*gotypes.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		named, ok := tn.Type().(*gotypes.Named)
+		if !ok || named.TypeParams().Len() > 0 || hasTextMethods(named) {
+			continue
+		}
+		if kind := types.FromTType(named).Kind(); kind != reflect.Struct && !isScalarKind(kind) {
+			continue
+		}
+		if by, ok := embedded[named]; ok {
+			g.skip(named, pkgerrors.Errorf("embedded by %s, generated methods would be promoted to it", by))
+			continue
+		}
+		list = append(list, named)
+		g.named[named] = true
+	}
+
+	fields := map[*gotypes.Named][]structField{}
+
+	for _, named := range list {
+		if typ := types.FromTType(named); typ.Kind() == reflect.Struct {
+			f, err := structFieldsOf(typ)
+			if err != nil {
+				g.skip(named, err)
+				delete(g.named, named)
+				continue
+			}
+			fields[named] = f
+		}
+	}
+
+	for _, named := range list {
+		if !g.named[named] {
+			continue
+		}
+		if f, ok := fields[named]; ok {
+			g.genStruct(named, f)
+		} else {
+			g.genScalar(named)
+		}
+	}
+
+	src := bytes.NewBuffer(nil)
+	src.WriteString(header + "\n\n")
+	fmt.Fprintf(src, "package %s\n\n", g.pkg.Name())
+	if g.useStrconv || g.useEncoding || g.useReflect {
+		src.WriteString("import (\n")
+		if g.useStrconv {
+			src.WriteString("\"strconv\"\n\n")
+		}
+		if g.useEncoding {
+			src.WriteString("encodingx \"github.com/utilsgo/x/encoding\"\n")
+		}
+		if g.useReflect {
+			src.WriteString("reflectx \"github.com/utilsgo/x/reflect\"\n")
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(g.buf.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// Skipped returns reasons of types skipped by Generate
+func (g *Generator) Skipped() []string {
+	return g.skipped
+}
+
+func (g *Generator) skip(named *gotypes.Named, err error) {
+	g.skipped = append(g.skipped, fmt.Sprintf("%s: %s", named.Obj().Name(), err))
+}
+
+// embeddedTypes returns named types of package embedded by structs of package, with name of the first struct.
+// methods of embedded types are promoted, so structs would be TextMarshaler by methods of their embedded types.
+func (g *Generator) embeddedTypes() map[*gotypes.Named]string {
+	embedded := map[*gotypes.Named]string{}
+
+	scope := g.pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*gotypes.TypeName)
+		if !ok {
+			continue
+		}
+		s, ok := tn.Type().Underlying().(*gotypes.Struct)
+		if !ok {
+			continue
+		}
+		for i := 0; i < s.NumFields(); i++ {
+			f := s.Field(i)
+			if !f.Anonymous() {
+				continue
+			}
+			t := f.Type()
+			if p, ok := t.(*gotypes.Pointer); ok {
+				t = p.Elem()
+			}
+			if named, ok := t.(*gotypes.Named); ok && named.Obj().Pkg() == g.pkg {
+				if _, ok := embedded[named.Origin()]; !ok {
+					embedded[named.Origin()] = name
+				}
+			}
+		}
+	}
+
+	return embedded
+}
+
+func hasTextMethods(named *gotypes.Named) bool {
+	for i := 0; i < named.NumMethods(); i++ {
+		switch named.Method(i).Name() {
+		case "AppendText", "MarshalText", "UnmarshalText":
+			return true
+		}
+	}
+	return false
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+func (g *Generator) printf(format string, args ...any) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+func (g *Generator) genScalar(named *gotypes.Named) {
+	name := named.Obj().Name()
+	kind := types.FromTType(named).Kind()
+
+	g.printf("\nfunc (v %s) AppendText(dst []byte) ([]byte, error) {\n", name)
+	g.printf("return %s, nil\n", g.appendScalar(kind, "v"))
+	g.printf("}\n")
+
+	g.genMarshalText(name)
+
+	g.printf("\nfunc (v *%s) UnmarshalText(data []byte) error {\n", name)
+	g.parseScalar(kind, "*v", name, "data")
+	g.printf("return nil\n")
+	g.printf("}\n")
+}
+
+func (g *Generator) genMarshalText(name string) {
+	g.printf("\nfunc (v %s) MarshalText() ([]byte, error) {\n", name)
+	g.printf("return v.AppendText(nil)\n")
+	g.printf("}\n")
+}
+
+type structField struct {
+	name string
+	// selector of field from the struct, like Part.C of fields of embedded struct
+	selector  string
+	field     types.StructField
+	omitempty bool
+}
+
+// structFieldsOf returns fields of struct typ sorted by name, same as fields converted by encoding.TextCodec.
+// returns error when fields could not be converted without reflection or are not converted as text at all.
+func structFieldsOf(typ types.Type) ([]structField, error) {
+	fields, err := appendStructFields(nil, typ, "")
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+
+	for i := 1; i < len(fields); i++ {
+		if fields[i].name == fields[i-1].name {
+			return nil, pkgerrors.Errorf("duplicated field name %q", fields[i].name)
+		}
+	}
+
+	return fields, nil
+}
+
+// appendStructFields appends fields of struct typ like types.EachField, with embedded structs inlined
+func appendStructFields(fields []structField, typ types.Type, selector string) ([]structField, error) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+
+		name, omitempty, keepNested := types.FieldDisplayName(f.Tag(), encodingx.TagJSON, f.Name())
+		if !ast.IsExported(f.Name()) || name == "-" {
+			continue
+		}
+
+		if f.Anonymous() {
+			switch types.Deref(f.Type()).Kind() {
+			case reflect.Struct:
+				if !keepNested {
+					// promoted fields of nil ptr could not be accessed
+					if f.Type().Kind() == reflect.Ptr {
+						return nil, pkgerrors.Errorf("embedded ptr field %s is not supported", f.Name())
+					}
+					var err error
+					if fields, err = appendStructFields(fields, f.Type(), selector+f.Name()+"."); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			case reflect.Interface:
+				continue
+			}
+		}
+
+		switch kind := f.Type().Kind(); kind {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			return nil, pkgerrors.Errorf("unsupported type %s of field %s", f.Type(), f.Name())
+		}
+
+		fields = append(fields, structField{
+			name:      name,
+			selector:  selector + f.Name(),
+			field:     f,
+			omitempty: omitempty,
+		})
+	}
+	return fields, nil
+}
+
+type fieldMode int
+
+const (
+	// fieldRuntime converts field by encoding.AppendText and encoding.UnmarshalText
+	fieldRuntime fieldMode = iota
+	// fieldOptions converts field by encoding.AppendFieldText and encoding.UnmarshalFieldText with its `text` tag
+	fieldOptions
+	// fieldInline converts field of basic type by strconv
+	fieldInline
+	// fieldMethod converts field by generated methods
+	fieldMethod
+)
+
+func (g *Generator) fieldMode(field types.StructField) fieldMode {
+	if _, ok := field.Tag().Lookup(encodingx.TagText); ok {
+		return fieldOptions
+	}
+	switch t := field.Type().Unwrap().(type) {
+	case *gotypes.Named:
+		if g.named[t] {
+			return fieldMethod
+		}
+	case *gotypes.Basic:
+		if isScalarKind(field.Type().Kind()) {
+			return fieldInline
+		}
+	}
+	return fieldRuntime
+}
+
+// genStruct generates methods encoding struct as k=v entries of fields sorted by name, same as encoding.TextCodec
+func (g *Generator) genStruct(named *gotypes.Named, fields []structField) {
+	name := named.Obj().Name()
+
+	g.printf("\nfunc (v %s) AppendText(dst []byte) ([]byte, error) {\n", name)
+	if len(fields) > 0 {
+		g.useEncoding = true
+
+		g.printf("first := len(dst)\n")
+		if g.escapesAny(fields) {
+			g.printf("var start int\n")
+		}
+		for _, f := range fields {
+			if g.fieldMode(f.field) != fieldInline {
+				g.printf("var err error\n")
+				break
+			}
+		}
+
+		for _, f := range fields {
+			expr := "v." + f.selector
+
+			g.printf("\n")
+			cond, checked := "", false
+			if f.omitempty {
+				cond, checked = g.nonEmpty(f.field, expr)
+			}
+			if checked {
+				g.printf("if %s {\n", cond)
+			}
+
+			g.printf("dst = encodingx.AppendTextEntryKey(dst, first, %q)\n", f.name)
+			if g.escapes(f.field) {
+				g.printf("start = len(dst)\n")
+			}
+
+			switch g.fieldMode(f.field) {
+			case fieldInline:
+				g.printf("dst = %s\n", g.appendScalar(f.field.Type().Kind(), expr))
+			case fieldMethod:
+				g.printf("dst, err = %s.AppendText(dst)\n", expr)
+				g.printf("if err != nil {\nreturn nil, err\n}\n")
+			case fieldOptions:
+				g.printf("dst, err = encodingx.AppendFieldText(dst, %q, %s)\n", string(f.field.Tag()), expr)
+				g.printf("if err != nil {\nreturn nil, err\n}\n")
+			default:
+				g.printf("dst, err = encodingx.AppendText(dst, %s)\n", expr)
+				g.printf("if err != nil {\nreturn nil, err\n}\n")
+			}
+
+			if g.escapes(f.field) {
+				g.printf("dst = encodingx.EscapeText(dst, start)\n")
+			}
+			if checked {
+				g.printf("}\n")
+			}
+		}
+	}
+	g.printf("return dst, nil\n")
+	g.printf("}\n")
+
+	g.genMarshalText(name)
+
+	g.useEncoding = true
+
+	g.printf("\nfunc (v *%s) UnmarshalText(data []byte) error {\n", name)
+	g.printf("return encodingx.EachTextEntry(data, func(key string, value []byte) error {\n")
+	if len(fields) > 0 {
+		g.printf("switch key {\n")
+		for _, f := range fields {
+			expr := "v." + f.selector
+
+			g.printf("case %q:\n", f.name)
+
+			switch g.fieldMode(f.field) {
+			case fieldInline:
+				g.parseScalar(f.field.Type().Kind(), expr, f.field.Type().String(), "value")
+			case fieldMethod:
+				g.printf("return %s.UnmarshalText(value)\n", expr)
+			case fieldOptions:
+				g.printf("return encodingx.UnmarshalFieldText(%q, &%s, value)\n", string(f.field.Tag()), expr)
+			default:
+				g.printf("return encodingx.UnmarshalText(&%s, value)\n", expr)
+			}
+		}
+		g.printf("}\n")
+	}
+	g.printf("return nil\n")
+	g.printf("})\n")
+	g.printf("}\n")
+}
+
+// escapes reports whether text of field may contain separators, text of numbers and bool never
+func (g *Generator) escapes(field types.StructField) bool {
+	return g.fieldMode(field) != fieldInline || field.Type().Kind() == reflect.String
+}
+
+func (g *Generator) escapesAny(fields []structField) bool {
+	for _, f := range fields {
+		if g.escapes(f.field) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty returns condition of non-empty expr of field, same as reflect.IsEmptyValue.
+// returns false when values of field are never empty, like complex or struct.
+func (g *Generator) nonEmpty(field types.StructField, expr string) (string, bool) {
+	if _, ok := field.Type().Unwrap().(*gotypes.Basic); !ok {
+		if field.Type().Kind() == reflect.Struct {
+			// struct is empty only as ZeroChecker
+			if _, ok := field.Type().MethodByName("IsZero"); !ok {
+				return "", false
+			}
+		}
+		g.useReflect = true
+		return fmt.Sprintf("!reflectx.IsEmptyValue(%s)", expr), true
+	}
+
+	switch kind := field.Type().Kind(); kind {
+	case reflect.String:
+		return fmt.Sprintf("%s != \"\"", expr), true
+	case reflect.Bool:
+		return expr, true
+	case reflect.Complex64, reflect.Complex128:
+		return "", false
+	}
+	return fmt.Sprintf("%s != 0", expr), true
+}
+
+// appendScalar returns expression appending text of expr with kind to dst, same as encoding.AppendText
+func (g *Generator) appendScalar(kind reflect.Kind, expr string) string {
+	switch kind {
+	case reflect.String:
+		return fmt.Sprintf("append(dst, %s...)", expr)
+	}
+
+	g.useStrconv = true
+
+	switch kind {
+	case reflect.Bool:
+		return fmt.Sprintf("strconv.AppendBool(dst, bool(%s))", expr)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("strconv.AppendInt(dst, int64(%s), 10)", expr)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprintf("strconv.AppendUint(dst, uint64(%s), 10)", expr)
+	case reflect.Float32:
+		return fmt.Sprintf("strconv.AppendFloat(dst, float64(%s), 'g', -1, 32)", expr)
+	case reflect.Float64:
+		return fmt.Sprintf("strconv.AppendFloat(dst, float64(%s), 'g', -1, 64)", expr)
+	case reflect.Complex64:
+		return fmt.Sprintf("append(dst, strconv.FormatComplex(complex128(%s), 'g', -1, 64)...)", expr)
+	}
+	return fmt.Sprintf("append(dst, strconv.FormatComplex(complex128(%s), 'g', -1, 128)...)", expr)
+}
+
+// parseScalar prints statements parsing data into target of typeName with kind, same as encoding.UnmarshalText
+func (g *Generator) parseScalar(kind reflect.Kind, target string, typeName string, data string) {
+	if kind == reflect.String {
+		g.printf("%s = %s(%s)\n", target, typeName, data)
+		return
+	}
+
+	g.useStrconv = true
+
+	switch kind {
+	case reflect.Bool:
+		g.printf("x, err := strconv.ParseBool(string(%s))\n", data)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		g.printf("x, err := strconv.ParseInt(string(%s), 10, %s)\n", data, bitSize(kind))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		g.printf("x, err := strconv.ParseUint(string(%s), 10, %s)\n", data, bitSize(kind))
+	case reflect.Float32, reflect.Float64:
+		g.printf("x, err := strconv.ParseFloat(string(%s), %s)\n", data, bitSize(kind))
+	case reflect.Complex64, reflect.Complex128:
+		g.printf("x, err := strconv.ParseComplex(string(%s), %s)\n", data, bitSize(kind))
+	}
+
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("%s = %s(x)\n", target, typeName)
+}
+
+func bitSize(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Uint:
+		return "strconv.IntSize"
+	case reflect.Uintptr:
+		return "32 << (^uintptr(0) >> 63)"
+	case reflect.Int8, reflect.Uint8:
+		return "8"
+	case reflect.Int16, reflect.Uint16:
+		return "16"
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return "32"
+	case reflect.Complex64, reflect.Int64, reflect.Uint64, reflect.Float64:
+		return "64"
+	}
+	return "128"
+}
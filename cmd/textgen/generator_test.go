@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+var update = flag.Bool("update", false, "update golden file")
+
+const (
+	typSource = "../../types/testdata/typ/types.go"
+	// typ is copy of types/testdata/typ with generated golden file,
+	// to check generated methods agree with encoding.MarshalText.
+	typCopy   = "internal/typ/types.go"
+	typGolden = "internal/typ/zz_generated.text.go"
+)
+
+func TestGenerate(t *testing.T) {
+	pkg := checkPackage(t, "github.com/utilsgo/x/types/testdata/typ", typSource)
+
+	g := NewGenerator(pkg)
+
+	data, err := g.Generate()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(g.Skipped()).To(Equal([]string{
+		"Bool: embedded by Struct, generated methods would be promoted to it",
+		"Struct: embedded by AnyStruct, generated methods would be promoted to it",
+	}))
+
+	if *update {
+		NewWithT(t).Expect(os.WriteFile(typGolden, data, 0o644)).To(BeNil())
+	}
+
+	golden, err := os.ReadFile(typGolden)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(string(golden)))
+
+	t.Run("copy of source", func(t *testing.T) {
+		src, err := os.ReadFile(typSource)
+		NewWithT(t).Expect(err).To(BeNil())
+		c, err := os.ReadFile(typCopy)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(c)).To(Equal(string(src)))
+	})
+}
+
+func TestGenerateSkipped(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "x.go")
+	src := `package x
+
+type Inner struct{ A string }
+
+type EmbeddedPtr struct {
+	*Inner
+}
+
+type Duplicated struct {
+	A string
+	B string ` + "`json:\"A\"`" + `
+}
+
+type WithChan struct {
+	C chan int
+}
+`
+	NewWithT(t).Expect(os.WriteFile(filename, []byte(src), 0o644)).To(BeNil())
+
+	g := NewGenerator(checkPackage(t, "x", filename))
+	_, err := g.Generate()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(g.Skipped()).To(Equal([]string{
+		"Inner: embedded by EmbeddedPtr, generated methods would be promoted to it",
+		`Duplicated: duplicated field name "A"`,
+		"EmbeddedPtr: embedded ptr field Inner is not supported",
+		"WithChan: unsupported type chan int of field C",
+	}))
+}
+
+func TestGenerateFile(t *testing.T) {
+	t.Run("existing file is kept when failed", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "zz_generated.text.go")
+		src := []byte(header + "\n\npackage x\n")
+		NewWithT(t).Expect(os.WriteFile(out, src, 0o644)).To(BeNil())
+
+		NewWithT(t).Expect(generate("", out)).NotTo(BeNil())
+
+		data, err := os.ReadFile(out)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(data).To(Equal(src))
+
+		entries, err := os.ReadDir(filepath.Dir(out))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(entries).To(HaveLen(1))
+	})
+
+	t.Run("not generated file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "x.go")
+		NewWithT(t).Expect(os.WriteFile(out, []byte("package x\n"), 0o644)).To(BeNil())
+		NewWithT(t).Expect(generate("", out)).NotTo(BeNil())
+	})
+
+	t.Run("write file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "zz_generated.text.go")
+		NewWithT(t).Expect(writeFile(out, []byte("x"))).To(BeNil())
+
+		data, err := os.ReadFile(out)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal("x"))
+	})
+}
+
+// checkPackage type-checks files of package from source,
+// same as types.NewPackage but without export data.
+func checkPackage(t *testing.T, path string, filenames ...string) *gotypes.Package {
+	fset := token.NewFileSet()
+
+	files := make([]*ast.File, len(filenames))
+	for i, filename := range filenames {
+		f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		NewWithT(t).Expect(err).To(BeNil())
+		files[i] = f
+	}
+
+	conf := gotypes.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(path, fset, files, nil)
+	NewWithT(t).Expect(err).To(BeNil())
+	return pkg
+}
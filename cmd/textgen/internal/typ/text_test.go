@@ -0,0 +1,110 @@
+package typ
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	encodingx "github.com/utilsgo/x/encoding"
+	"github.com/utilsgo/x/types/testdata/typ/typ"
+)
+
+var values = []any{
+	String("a,b=c\\"),
+	String(""),
+	Int(-1),
+	Int8(-8),
+	Int16(-16),
+	Int32(-32),
+	Int64(-64),
+	Uint(1),
+	Uint8(8),
+	Uint16(16),
+	Uint32(32),
+	Uint64(64),
+	Uintptr(1),
+	Float32(0.1),
+	Float64(-0.1),
+	Complex64(1 + 2i),
+	Complex128(-1.5 - 2i),
+	Part{C: "c=1"},
+	Part{},
+	DeepCompose{
+		Struct: Struct{
+			A:     "a=",
+			B:     "b,\\",
+			Bool:  true,
+			Part:  typ.Part{C: "c"},
+			Part2: Part{C: "x,y"},
+		},
+	},
+	DeepCompose{},
+}
+
+func TestGeneratedText(t *testing.T) {
+	for i := range values {
+		v := values[i]
+
+		t.Run(fmt.Sprintf("%T(%v)", v, v), func(t *testing.T) {
+			generated, err := v.(encoding.TextMarshaler).MarshalText()
+			NewWithT(t).Expect(err).To(BeNil())
+
+			// text of the runtime codec, without generated methods of the type
+			rv := reflect.ValueOf(v)
+			plainType := plainTypeOf(rv.Type())
+			reflective, err := encodingx.MarshalText(rv.Convert(plainType).Interface())
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(generated)).To(Equal(string(reflective)))
+
+			decoded := reflect.New(rv.Type())
+			NewWithT(t).Expect(decoded.Interface().(encoding.TextUnmarshaler).UnmarshalText(generated)).To(BeNil())
+			NewWithT(t).Expect(decoded.Elem().Interface()).To(Equal(v))
+
+			plain := reflect.New(plainType)
+			NewWithT(t).Expect(encodingx.UnmarshalText(plain.Interface(), generated)).To(BeNil())
+			NewWithT(t).Expect(plain.Elem().Convert(rv.Type()).Interface()).To(Equal(v))
+		})
+	}
+
+	t.Run("embedded types are not generated", func(t *testing.T) {
+		for _, v := range []any{Bool(true), Struct{}} {
+			_, ok := v.(encoding.TextMarshaler)
+			NewWithT(t).Expect(ok).To(BeFalse())
+		}
+
+		data, err := encodingx.MarshalJSON(Struct{A: "a", Bool: true})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(`{"a":"a","b":"","bool":true,"c":"","Part2":"c="}`))
+	})
+}
+
+// plain types have same fields as generated structs, but without methods
+type (
+	plainPart        Part
+	plainDeepCompose DeepCompose
+)
+
+func plainTypeOf(typ reflect.Type) reflect.Type {
+	switch typ {
+	case reflect.TypeOf(Part{}):
+		return reflect.TypeOf(plainPart{})
+	case reflect.TypeOf(DeepCompose{}):
+		return reflect.TypeOf(plainDeepCompose{})
+	}
+	return basicTypes[typ.Kind()]
+}
+
+var basicTypes = map[reflect.Kind]reflect.Type{}
+
+func init() {
+	for _, v := range []any{
+		"", false,
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0), uintptr(0),
+		float32(0), float64(0), complex64(0), complex128(0),
+	} {
+		basicTypes[reflect.TypeOf(v).Kind()] = reflect.TypeOf(v)
+	}
+}
@@ -0,0 +1,365 @@
+// Code generated by textgen. DO NOT EDIT.
+
+package typ
+
+import (
+	"strconv"
+
+	encodingx "github.com/utilsgo/x/encoding"
+	reflectx "github.com/utilsgo/x/reflect"
+)
+
+func (v Complex128) AppendText(dst []byte) ([]byte, error) {
+	return append(dst, strconv.FormatComplex(complex128(v), 'g', -1, 128)...), nil
+}
+
+func (v Complex128) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Complex128) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseComplex(string(data), 128)
+	if err != nil {
+		return err
+	}
+	*v = Complex128(x)
+	return nil
+}
+
+func (v Complex64) AppendText(dst []byte) ([]byte, error) {
+	return append(dst, strconv.FormatComplex(complex128(v), 'g', -1, 64)...), nil
+}
+
+func (v Complex64) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Complex64) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseComplex(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*v = Complex64(x)
+	return nil
+}
+
+func (v DeepCompose) AppendText(dst []byte) ([]byte, error) {
+	first := len(dst)
+	var start int
+	var err error
+
+	dst = encodingx.AppendTextEntryKey(dst, first, "Part2")
+	start = len(dst)
+	dst, err = v.Struct.Part2.AppendText(dst)
+	if err != nil {
+		return nil, err
+	}
+	dst = encodingx.EscapeText(dst, start)
+
+	dst = encodingx.AppendTextEntryKey(dst, first, "a")
+	start = len(dst)
+	dst = append(dst, v.Struct.A...)
+	dst = encodingx.EscapeText(dst, start)
+
+	dst = encodingx.AppendTextEntryKey(dst, first, "b")
+	start = len(dst)
+	dst = append(dst, v.Struct.B...)
+	dst = encodingx.EscapeText(dst, start)
+
+	if !reflectx.IsEmptyValue(v.Struct.Bool) {
+		dst = encodingx.AppendTextEntryKey(dst, first, "bool")
+		start = len(dst)
+		dst, err = encodingx.AppendText(dst, v.Struct.Bool)
+		if err != nil {
+			return nil, err
+		}
+		dst = encodingx.EscapeText(dst, start)
+	}
+
+	dst = encodingx.AppendTextEntryKey(dst, first, "c")
+	start = len(dst)
+	dst = append(dst, v.Struct.Part.C...)
+	dst = encodingx.EscapeText(dst, start)
+	return dst, nil
+}
+
+func (v DeepCompose) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *DeepCompose) UnmarshalText(data []byte) error {
+	return encodingx.EachTextEntry(data, func(key string, value []byte) error {
+		switch key {
+		case "Part2":
+			return v.Struct.Part2.UnmarshalText(value)
+		case "a":
+			v.Struct.A = string(value)
+		case "b":
+			v.Struct.B = string(value)
+		case "bool":
+			return encodingx.UnmarshalText(&v.Struct.Bool, value)
+		case "c":
+			v.Struct.Part.C = string(value)
+		}
+		return nil
+	})
+}
+
+func (v Float32) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendFloat(dst, float64(v), 'g', -1, 32), nil
+}
+
+func (v Float32) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Float32) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseFloat(string(data), 32)
+	if err != nil {
+		return err
+	}
+	*v = Float32(x)
+	return nil
+}
+
+func (v Float64) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendFloat(dst, float64(v), 'g', -1, 64), nil
+}
+
+func (v Float64) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Float64) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*v = Float64(x)
+	return nil
+}
+
+func (v Int) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(v), 10), nil
+}
+
+func (v Int) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Int) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseInt(string(data), 10, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*v = Int(x)
+	return nil
+}
+
+func (v Int16) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(v), 10), nil
+}
+
+func (v Int16) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Int16) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseInt(string(data), 10, 16)
+	if err != nil {
+		return err
+	}
+	*v = Int16(x)
+	return nil
+}
+
+func (v Int32) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(v), 10), nil
+}
+
+func (v Int32) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Int32) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseInt(string(data), 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = Int32(x)
+	return nil
+}
+
+func (v Int64) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(v), 10), nil
+}
+
+func (v Int64) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Int64) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = Int64(x)
+	return nil
+}
+
+func (v Int8) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendInt(dst, int64(v), 10), nil
+}
+
+func (v Int8) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Int8) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseInt(string(data), 10, 8)
+	if err != nil {
+		return err
+	}
+	*v = Int8(x)
+	return nil
+}
+
+func (v Part) AppendText(dst []byte) ([]byte, error) {
+	first := len(dst)
+	var start int
+
+	dst = encodingx.AppendTextEntryKey(dst, first, "c")
+	start = len(dst)
+	dst = append(dst, v.C...)
+	dst = encodingx.EscapeText(dst, start)
+	return dst, nil
+}
+
+func (v Part) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Part) UnmarshalText(data []byte) error {
+	return encodingx.EachTextEntry(data, func(key string, value []byte) error {
+		switch key {
+		case "c":
+			v.C = string(value)
+		}
+		return nil
+	})
+}
+
+func (v String) AppendText(dst []byte) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+func (v String) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *String) UnmarshalText(data []byte) error {
+	*v = String(data)
+	return nil
+}
+
+func (v Uint) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uint) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uint) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*v = Uint(x)
+	return nil
+}
+
+func (v Uint16) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uint16) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uint16) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, 16)
+	if err != nil {
+		return err
+	}
+	*v = Uint16(x)
+	return nil
+}
+
+func (v Uint32) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uint32) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uint32) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = Uint32(x)
+	return nil
+}
+
+func (v Uint64) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uint64) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uint64) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = Uint64(x)
+	return nil
+}
+
+func (v Uint8) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uint8) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uint8) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, 8)
+	if err != nil {
+		return err
+	}
+	*v = Uint8(x)
+	return nil
+}
+
+func (v Uintptr) AppendText(dst []byte) ([]byte, error) {
+	return strconv.AppendUint(dst, uint64(v), 10), nil
+}
+
+func (v Uintptr) MarshalText() ([]byte, error) {
+	return v.AppendText(nil)
+}
+
+func (v *Uintptr) UnmarshalText(data []byte) error {
+	x, err := strconv.ParseUint(string(data), 10, 32<<(^uintptr(0)>>63))
+	if err != nil {
+		return err
+	}
+	*v = Uintptr(x)
+	return nil
+}
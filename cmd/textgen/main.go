@@ -0,0 +1,100 @@
+// Command textgen generates AppendText, MarshalText and UnmarshalText methods
+// for named scalar types and structs of the package in current directory.
+// Generated methods encode same text as encoding.MarshalText without reflection,
+// and structs are encoded as k=v entries of fields named by the json tag.
+// Types embedded by structs are skipped and logged, as their methods would be promoted.
+//
+//	//go:generate go run github.com/utilsgo/x/cmd/textgen
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/utilsgo/x/types"
+)
+
+const header = "// Code generated by textgen. DO NOT EDIT."
+
+func main() {
+	out := flag.String("out", "zz_generated.text.go", "output file")
+	flag.Parse()
+
+	if err := generate(".", *out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate writes methods of package at importPath to out.
+// Existing out is moved aside while loading package, as its methods should not be loaded,
+// and it is restored unless the new one is written.
+func generate(importPath string, out string) (err error) {
+	if data, e := os.ReadFile(out); e == nil {
+		if !bytes.HasPrefix(data, []byte(header)) {
+			return pkgerrors.Errorf("%s is not generated by textgen", out)
+		}
+
+		backup := out + ".bak"
+		if err := os.Rename(out, backup); err != nil {
+			return err
+		}
+
+		// restore on errors and panics of loading package
+		defer func() {
+			r := recover()
+			if r == nil && err == nil {
+				_ = os.Remove(backup)
+				return
+			}
+			if e := os.Rename(backup, out); e != nil && err == nil {
+				err = e
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+
+	pkg := types.NewPackage(importPath)
+	if pkg == nil {
+		return pkgerrors.Errorf("load package %q failed", importPath)
+	}
+
+	g := NewGenerator(pkg)
+
+	data, err := g.Generate()
+	if err != nil {
+		return err
+	}
+
+	for _, reason := range g.Skipped() {
+		log.Printf("skip %s", reason)
+	}
+
+	return writeFile(out, data)
+}
+
+// writeFile writes data to temp file then renames it to filename, so filename is complete or untouched
+func writeFile(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,120 @@
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type Version struct {
+	Major, Minor int
+}
+
+func (v Version) AppendText(b []byte) ([]byte, error) {
+	b = append(b, 'v')
+	b = fmt.Appendf(b, "%d.%d", v.Major, v.Minor)
+	return b, nil
+}
+
+func TestAppendText(t *testing.T) {
+	values := []struct {
+		v    any
+		text string
+	}{
+		{"s", "s"},
+		{1, "1"},
+		{ptr.Int8(-1), "-1"},
+		{uint16(2), "2"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{complex64(1 - 2i), "(1-2i)"},
+		{complex128(complex(1, 0)), "(1+0i)"},
+		{[]byte("111"), "MTEx"},
+		{Duration(time.Second), "1s"},
+		{time.Second, "1s"},
+		{[]string{"a,b", "c"}, "a\\,b,c"},
+		{map[string]string{"a": "b=c"}, "a=b\\=c"},
+		{Version{1, 2}, "v1.2"},
+		{[]Version{{1, 2}}, "v1.2"},
+		{(*int)(nil), ""},
+	}
+
+	for _, c := range values {
+		t.Run(fmt.Sprintf("%T", c.v), func(t *testing.T) {
+			text, err := MarshalText(c.v)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(c.text))
+
+			dst, err := AppendText([]byte("prefix:"), c.v)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(dst)).To(Equal("prefix:" + c.text))
+		})
+	}
+
+	t.Run("MarshalText nil", func(t *testing.T) {
+		text, err := MarshalText((*int)(nil))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(text).To(BeNil())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := AppendText(nil, make(chan int))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+var benchmarkTextValues = []struct {
+	name string
+	v    any
+}{
+	{"string", "string"},
+	{"int", -123456},
+	{"uint64", uint64(123456789)},
+	{"float64", 3.1415926},
+	{"bool", true},
+	{"time.Duration", 90 * time.Second},
+	{"TextMarshaler", Duration(time.Second)},
+	{"time.Time", time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)},
+	{"[]int", []int{1, 2, 3, 4}},
+	{"[]string", []string{"a,b", "c", "d"}},
+	{"map[string]int", map[string]int{"a": 1, "b": 2}},
+}
+
+func BenchmarkAppendText(b *testing.B) {
+	for _, c := range benchmarkTextValues {
+		v := c.v
+
+		b.Run("MarshalText/"+c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = MarshalText(v)
+			}
+		})
+
+		b.Run("AppendText/"+c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			buf := make([]byte, 0, 256)
+			for i := 0; i < b.N; i++ {
+				buf, _ = AppendText(buf[:0], v)
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalText(b *testing.B) {
+	for _, c := range benchmarkTextValues {
+		text, _ := MarshalText(c.v)
+		p := reflect.New(reflect.TypeOf(c.v)).Interface()
+
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = UnmarshalText(p, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package encoding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BoolVocabulary is words of true and false, which are compared case-insensitively
+type BoolVocabulary struct {
+	True  []string
+	False []string
+}
+
+// LenientBoolVocabulary accepts words which operators write in env files and configs
+var LenientBoolVocabulary = &BoolVocabulary{
+	True:  []string{"1", "t", "true", "y", "yes", "on", "enable", "enabled"},
+	False: []string{"0", "f", "false", "n", "no", "off", "disable", "disabled"},
+}
+
+// Parse returns bool of s, error lists all accepted words
+func (v *BoolVocabulary) Parse(s string) (bool, error) {
+	for _, w := range v.True {
+		if strings.EqualFold(w, s) {
+			return true, nil
+		}
+	}
+	for _, w := range v.False {
+		if strings.EqualFold(w, s) {
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("invalid bool %q, should be one of %s for true or %s for false",
+		s, strings.Join(v.True, ", "), strings.Join(v.False, ", "))
+}
+
+func (c *TextCodec) parseBool(data []byte) (bool, error) {
+	if c.boolVocabulary == nil {
+		return strconv.ParseBool(string(data))
+	}
+	return c.boolVocabulary.Parse(string(data))
+}
@@ -0,0 +1,58 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTextCodecBoolVocabulary(t *testing.T) {
+	c := NewTextCodec(WithBoolVocabulary(LenientBoolVocabulary))
+
+	for text, expect := range map[string]bool{
+		"yes":      true,
+		"ON":       true,
+		"Enabled":  true,
+		"true":     true,
+		"no":       false,
+		"Off":      false,
+		"DISABLED": false,
+		"0":        false,
+	} {
+		var b bool
+		NewWithT(t).Expect(c.UnmarshalText(&b, []byte(text))).To(BeNil())
+		NewWithT(t).Expect(b).To(Equal(expect))
+	}
+
+	var b bool
+	err := c.UnmarshalText(&b, []byte("maybe"))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	NewWithT(t).Expect(err.Error()).To(ContainSubstring("enabled"))
+
+	t.Run("custom", func(t *testing.T) {
+		c := NewTextCodec(WithBoolVocabulary(&BoolVocabulary{True: []string{"ja"}, False: []string{"nein"}}))
+
+		var b NamedBool
+		NewWithT(t).Expect(c.UnmarshalText(&b, []byte("JA"))).To(BeNil())
+		NewWithT(t).Expect(b).To(Equal(NamedBool(true)))
+		NewWithT(t).Expect(c.UnmarshalText(&b, []byte("yes"))).NotTo(BeNil())
+	})
+
+	t.Run("strict by default", func(t *testing.T) {
+		var b bool
+		NewWithT(t).Expect(UnmarshalText(&b, []byte("yes"))).NotTo(BeNil())
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		v := struct {
+			Debug bool `env:"DEBUG" text:"bool=lenient"`
+		}{}
+		NewWithT(t).Expect(UnmarshalEnv(&v, WithEnvMap(map[string]string{"DEBUG": "on"}))).To(BeNil())
+		NewWithT(t).Expect(v.Debug).To(BeTrue())
+
+		_, err := DefaultTextCodec.ForField(`text:"bool=strict"`)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+type NamedBool bool
@@ -0,0 +1,126 @@
+package encoding
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// BytesEncoding is the text representation of []byte
+type BytesEncoding int
+
+const (
+	BytesBase64       BytesEncoding = iota // base64.StdEncoding
+	BytesBase64URL                         // base64.URLEncoding
+	BytesBase64Raw                         // base64.RawStdEncoding
+	BytesBase64RawURL                      // base64.RawURLEncoding
+	BytesHex                               // hex
+	BytesBase32                            // base32.StdEncoding
+)
+
+var bytesEncodingNames = map[BytesEncoding]string{
+	BytesBase64:       "std",
+	BytesBase64URL:    "url",
+	BytesBase64Raw:    "rawstd",
+	BytesBase64RawURL: "rawurl",
+	BytesHex:          "hex",
+	BytesBase32:       "base32",
+}
+
+func ParseBytesEncoding(s string) (BytesEncoding, error) {
+	for e, name := range bytesEncodingNames {
+		if name == s {
+			return e, nil
+		}
+	}
+	return BytesBase64, fmt.Errorf("unknown bytes encoding %q", s)
+}
+
+func (e BytesEncoding) String() string {
+	if name, ok := bytesEncodingNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("BytesEncoding(%d)", int(e))
+}
+
+func (e BytesEncoding) MarshalText() ([]byte, error) {
+	if _, ok := bytesEncodingNames[e]; !ok {
+		return nil, fmt.Errorf("unknown bytes encoding %d", int(e))
+	}
+	return []byte(e.String()), nil
+}
+
+func (e *BytesEncoding) UnmarshalText(text []byte) error {
+	enc, err := ParseBytesEncoding(string(text))
+	if err != nil {
+		return err
+	}
+	*e = enc
+	return nil
+}
+
+func (e BytesEncoding) base64() *base64.Encoding {
+	switch e {
+	case BytesBase64URL:
+		return base64.URLEncoding
+	case BytesBase64Raw:
+		return base64.RawStdEncoding
+	case BytesBase64RawURL:
+		return base64.RawURLEncoding
+	}
+	return base64.StdEncoding
+}
+
+func (e BytesEncoding) appendEncode(dst []byte, data []byte) []byte {
+	switch e {
+	case BytesHex:
+		n := len(dst)
+		dst = append(dst, make([]byte, hex.EncodedLen(len(data)))...)
+		hex.Encode(dst[n:], data)
+		return dst
+	case BytesBase32:
+		n := len(dst)
+		dst = append(dst, make([]byte, base32.StdEncoding.EncodedLen(len(data)))...)
+		base32.StdEncoding.Encode(dst[n:], data)
+		return dst
+	}
+	return appendBase64Encoded(dst, e.base64(), data)
+}
+
+func (e BytesEncoding) decode(data []byte) ([]byte, error) {
+	switch e {
+	case BytesHex:
+		d := make([]byte, hex.DecodedLen(len(data)))
+		n, err := hex.Decode(d, data)
+		if err != nil {
+			return nil, err
+		}
+		return d[:n], nil
+	case BytesBase32:
+		d := make([]byte, base32.StdEncoding.DecodedLen(len(data)))
+		n, err := base32.StdEncoding.Decode(d, data)
+		if err != nil {
+			return nil, err
+		}
+		return d[:n], nil
+	}
+	return fromBase64Encoded(e.base64(), data)
+}
+
+// flow encoding/json did
+func fromBase64Encoded(encoding *base64.Encoding, data []byte) ([]byte, error) {
+	d := make([]byte, encoding.DecodedLen(len(data)))
+	n, err := encoding.Decode(d, data)
+	if err != nil {
+		return nil, err
+	}
+	return d[:n], nil
+}
+
+func appendBase64Encoded(dst []byte, encoding *base64.Encoding, data []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, encoding.EncodedLen(len(data)))...)
+	encoding.Encode(dst[n:], data)
+	return dst
+}
@@ -0,0 +1,92 @@
+package encoding
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type Bytes []byte
+
+func TestBytesEncoding(t *testing.T) {
+	data := []byte("\xfb\xff?>")
+
+	cases := []struct {
+		enc  BytesEncoding
+		text string
+	}{
+		{BytesBase64, "+/8/Pg=="},
+		{BytesBase64URL, "-_8_Pg=="},
+		{BytesBase64Raw, "+/8/Pg"},
+		{BytesBase64RawURL, "-_8_Pg"},
+		{BytesHex, "fbff3f3e"},
+		{BytesBase32, "7P7T6PQ="},
+	}
+
+	for _, c := range cases {
+		t.Run(c.enc.String(), func(t *testing.T) {
+			codec := NewTextCodec(WithBytesEncoding(c.enc))
+
+			text, err := codec.MarshalText(data)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(c.text))
+
+			d := make([]byte, 0)
+			err = codec.UnmarshalText(&d, text)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(d).To(Equal(data))
+
+			named := Bytes{}
+			err = codec.UnmarshalText(reflect.ValueOf(&named).Elem(), text)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect([]byte(named)).To(Equal(data))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		d := make([]byte, 0)
+		err := NewTextCodec(WithBytesEncoding(BytesHex)).UnmarshalText(&d, []byte("x"))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("text", func(t *testing.T) {
+		enc := BytesEncoding(0)
+		NewWithT(t).Expect(UnmarshalText(&enc, []byte("rawurl"))).To(BeNil())
+		NewWithT(t).Expect(enc).To(Equal(BytesBase64RawURL))
+		NewWithT(t).Expect(UnmarshalText(&enc, []byte("base62"))).NotTo(BeNil())
+	})
+}
+
+func TestTextCodecForField(t *testing.T) {
+	type Signed struct {
+		Digest  []byte `form:"digest" text:"bytes=hex"`
+		Payload []byte `form:"payload"`
+		Token   []byte `form:"token" text:"bytes=rawurl"`
+	}
+
+	s := Signed{
+		Digest:  []byte{0xde, 0xad},
+		Payload: []byte("111"),
+		Token:   []byte{0xfb, 0xff},
+	}
+
+	values, err := MarshalForm(s)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(values.Get("digest")).To(Equal("dead"))
+	NewWithT(t).Expect(values.Get("payload")).To(Equal("MTEx"))
+	NewWithT(t).Expect(values.Get("token")).To(Equal("-_8"))
+
+	s2 := Signed{}
+	err = UnmarshalForm(values, &s2)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(s2).To(Equal(s))
+
+	t.Run("invalid tag", func(t *testing.T) {
+		_, err := DefaultTextCodec.ForField(`text:"bytes=base62"`)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+
+		_, err = DefaultTextCodec.ForField(`text:"unknown"`)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,757 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TypeCodec converts values of single type to and from text.
+// Conversion is resolved once by CodecFor, instead of type switches on each call,
+// so it fits loops over fields or elements of same type.
+type TypeCodec struct {
+	typ reflect.Type
+	err error
+
+	appendValue    func(dst []byte, rv reflect.Value) ([]byte, error)
+	unmarshalValue func(rv reflect.Value, data []byte) error
+}
+
+// CodecFor returns TypeCodec of typ by DefaultTextCodec
+func CodecFor(typ reflect.Type) (*TypeCodec, error) {
+	return DefaultTextCodec.CodecFor(typ)
+}
+
+// CodecFor returns TypeCodec of typ, which is cached per type.
+// Cache is dropped when any codec registered to registry of c.
+// Returns error when values of typ could not be encoded as text, like chan or struct with chan field.
+func (c *TextCodec) CodecFor(typ reflect.Type) (*TypeCodec, error) {
+	tc := c.codecFor(typ)
+	if tc.err != nil {
+		return nil, tc.err
+	}
+	return tc, nil
+}
+
+// Type returns type of values converted
+func (tc *TypeCodec) Type() reflect.Type {
+	return tc.typ
+}
+
+// AppendValue appends text of rv to dst, rv should be of Type.
+func (tc *TypeCodec) AppendValue(dst []byte, rv reflect.Value) ([]byte, error) {
+	return tc.appendValue(dst, rv)
+}
+
+// UnmarshalValue decodes data into rv, rv should be addressable value of Type.
+func (tc *TypeCodec) UnmarshalValue(rv reflect.Value, data []byte) error {
+	if !rv.CanAddr() {
+		return pkgerrors.Errorf("unmarshal text need addressable value, but got %s", rv.Type())
+	}
+	return tc.unmarshalValue(rv, data)
+}
+
+// AppendPointer appends text of value which p points to, p should be pointer to value of Type.
+func (tc *TypeCodec) AppendPointer(dst []byte, p unsafe.Pointer) ([]byte, error) {
+	return tc.appendValue(dst, reflect.NewAt(tc.typ, p).Elem())
+}
+
+// UnmarshalPointer decodes data into value which p points to, p should be pointer to value of Type.
+func (tc *TypeCodec) UnmarshalPointer(p unsafe.Pointer, data []byte) error {
+	return tc.unmarshalValue(reflect.NewAt(tc.typ, p).Elem(), data)
+}
+
+// marshalValue returns text of rv like TextCodec.MarshalText
+func (tc *TypeCodec) marshalValue(c *TextCodec, rv reflect.Value) ([]byte, error) {
+	if isNil(rv) {
+		if c.nullPolicy == NullAsText {
+			return c.appendNull([]byte{}), nil
+		}
+		return nil, nil
+	}
+	return tc.appendValue([]byte{}, rv)
+}
+
+type typeCodecCache struct {
+	generation  uint64
+	codecs      sync.Map
+	fields      sync.Map
+	validations sync.Map
+	mu          sync.Mutex
+}
+
+// typeCodecs holds cache of TypeCodec for TextCodec
+type typeCodecs struct {
+	cache atomic.Pointer[typeCodecCache]
+}
+
+func (c *TextCodec) typeCodecCache() *typeCodecCache {
+	generation := c.registry.generation.Load()
+
+	cache := c.codecs.cache.Load()
+	if cache == nil || cache.generation != generation {
+		cache = &typeCodecCache{generation: generation}
+		c.codecs.cache.Store(cache)
+	}
+	return cache
+}
+
+func (c *TextCodec) codecFor(typ reflect.Type) *TypeCodec {
+	cache := c.typeCodecCache()
+
+	if tc, ok := cache.codecs.Load(typ); ok {
+		return tc.(*TypeCodec)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if tc, ok := cache.codecs.Load(typ); ok {
+		return tc.(*TypeCodec)
+	}
+
+	// codecs are only stored when completed, building ones are shared for recursive types
+	building := map[reflect.Type]*TypeCodec{}
+	tc := c.buildCodec(cache, typ, building)
+	for t, b := range building {
+		cache.codecs.Store(t, b)
+	}
+	return tc
+}
+
+func (c *TextCodec) buildCodec(cache *typeCodecCache, typ reflect.Type, building map[reflect.Type]*TypeCodec) *TypeCodec {
+	if tc, ok := cache.codecs.Load(typ); ok {
+		return tc.(*TypeCodec)
+	}
+	if tc, ok := building[typ]; ok {
+		return tc
+	}
+
+	tc := &TypeCodec{typ: typ}
+	building[typ] = tc
+
+	elemCodec := func(t reflect.Type) *TypeCodec {
+		return c.buildCodec(cache, t, building)
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		c.buildPtrCodec(tc, elemCodec(typ.Elem()))
+	case reflect.Interface:
+		c.buildInterfaceCodec(tc)
+	default:
+		tc.appendValue, tc.err = c.appendFuncOf(typ, elemCodec)
+		tc.unmarshalValue = c.unmarshalFuncOf(typ, elemCodec)
+	}
+
+	return tc
+}
+
+func (c *TextCodec) buildPtrCodec(tc *TypeCodec, elem *TypeCodec) {
+	tc.err = elem.err
+
+	tc.appendValue = func(dst []byte, rv reflect.Value) ([]byte, error) {
+		if rv.IsNil() {
+			return c.appendNull(dst), nil
+		}
+		return elem.appendValue(dst, rv.Elem())
+	}
+
+	tc.unmarshalValue = func(rv reflect.Value, data []byte) error {
+		if c.IsNullText(data) && rv.CanSet() {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			if !rv.CanSet() {
+				return pkgerrors.Errorf("unmarshal text need settable value, but got nil %s", rv.Type())
+			}
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return elem.unmarshalValue(rv.Elem(), data)
+	}
+}
+
+// buildInterfaceCodec converts by dynamic type of values, and decoding into interface is ignored
+func (c *TextCodec) buildInterfaceCodec(tc *TypeCodec) {
+	tc.appendValue = func(dst []byte, rv reflect.Value) ([]byte, error) {
+		if rv.IsNil() {
+			return c.appendNull(dst), nil
+		}
+		elem := rv.Elem()
+		return c.codecFor(elem.Type()).appendValue(dst, elem)
+	}
+
+	tc.unmarshalValue = func(rv reflect.Value, data []byte) error {
+		return nil
+	}
+}
+
+var (
+	textAppenderType    = reflect.TypeOf((*TextAppender)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+type appendFunc func(dst []byte, rv reflect.Value) ([]byte, error)
+
+// appendMethod is conversion by methods of type, which need value to be interface.
+// value of pointer receiver methods should be addressable.
+type appendMethod struct {
+	addr   bool
+	append func(dst []byte, v any) ([]byte, error)
+}
+
+// appendFuncOf returns appendFunc of non-pointer typ.
+// Conversion is checked in order of registered codec, time.Time, TextAppender, encoding.TextMarshaler,
+// encoding.BinaryMarshaler, registered parse func, and at last kind of typ.
+func (c *TextCodec) appendFuncOf(typ reflect.Type, elemCodec func(t reflect.Type) *TypeCodec) (appendFunc, error) {
+	methods := make([]appendMethod, 0)
+
+	if registered, ok := c.registry.codec(typ); ok {
+		methods = append(methods, appendMethod{append: func(dst []byte, v any) ([]byte, error) {
+			text, err := registered.marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return append(dst, text...), nil
+		}})
+	}
+
+	if typ == timeType {
+		methods = append(methods, appendMethod{append: func(dst []byte, v any) ([]byte, error) {
+			return v.(time.Time).AppendFormat(dst, c.timeLayout), nil
+		}})
+	}
+
+	for _, m := range []struct {
+		iface  reflect.Type
+		append func(dst []byte, v any) ([]byte, error)
+	}{
+		{textAppenderType, func(dst []byte, v any) ([]byte, error) {
+			return v.(TextAppender).AppendText(dst)
+		}},
+		{textMarshalerType, func(dst []byte, v any) ([]byte, error) {
+			text, err := v.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return append(dst, text...), nil
+		}},
+		{binaryMarshalerType, func(dst []byte, v any) ([]byte, error) {
+			data, err := v.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			return c.bytesEncoding.appendEncode(dst, data), nil
+		}},
+	} {
+		if typ.Implements(m.iface) {
+			methods = append(methods, appendMethod{append: m.append})
+		} else if reflect.PtrTo(typ).Implements(m.iface) {
+			methods = append(methods, appendMethod{addr: true, append: m.append})
+		}
+	}
+
+	if registered, ok := c.registry.parser(typ); ok {
+		methods = append(methods, appendMethod{append: func(dst []byte, v any) ([]byte, error) {
+			text, err := registered.marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return append(dst, text...), nil
+		}})
+	}
+
+	kindAppend, err := c.kindAppendFuncOf(typ, elemCodec)
+
+	if len(methods) == 0 {
+		return kindAppend, err
+	}
+
+	if !methods[0].addr {
+		// values of unexported fields could not be interface
+		m := methods[0]
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			if rv.CanInterface() {
+				return m.append(dst, rv.Interface())
+			}
+			return kindAppend(dst, rv)
+		}, nil
+	}
+
+	return func(dst []byte, rv reflect.Value) ([]byte, error) {
+		if rv.CanInterface() {
+			for _, m := range methods {
+				if !m.addr {
+					return m.append(dst, rv.Interface())
+				}
+				if rv.CanAddr() {
+					return m.append(dst, rv.Addr().Interface())
+				}
+			}
+		}
+		return kindAppend(dst, rv)
+	}, nil
+}
+
+func (c *TextCodec) kindAppendFuncOf(typ reflect.Type, elemCodec func(t reflect.Type) *TypeCodec) (appendFunc, error) {
+	switch typ.Kind() {
+	case reflect.Slice:
+		if et := typ.Elem(); et.PkgPath() == "" && et.Kind() == reflect.Uint8 {
+			return func(dst []byte, rv reflect.Value) ([]byte, error) {
+				return c.bytesEncoding.appendEncode(dst, rv.Bytes()), nil
+			}, nil
+		}
+		elem := elemCodec(typ.Elem())
+		return c.listAppendFunc(elem), elem.err
+	case reflect.Array:
+		elem := elemCodec(typ.Elem())
+		return c.listAppendFunc(elem), elem.err
+	case reflect.Map:
+		key, elem := elemCodec(typ.Key()), elemCodec(typ.Elem())
+		if key.err != nil {
+			return c.mapAppendFunc(key, elem), key.err
+		}
+		return c.mapAppendFunc(key, elem), elem.err
+	case reflect.Struct:
+		fields, err := c.textFieldsOf(typ, elemCodec)
+		if err != nil {
+			return func(dst []byte, rv reflect.Value) ([]byte, error) {
+				return nil, err
+			}, err
+		}
+		return c.structAppendFunc(fields), nil
+	case reflect.String:
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return append(dst, rv.String()...), nil
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == durationType {
+			return func(dst []byte, rv reflect.Value) ([]byte, error) {
+				return append(dst, time.Duration(rv.Int()).String()...), nil
+			}, nil
+		}
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return c.appendInt(dst, rv.Int()), nil
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return c.appendUint(dst, rv.Uint()), nil
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		bitSize := typ.Bits()
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return strconv.AppendFloat(dst, rv.Float(), 'g', -1, bitSize), nil
+		}, nil
+	case reflect.Complex64, reflect.Complex128:
+		bitSize := typ.Bits()
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return appendComplex(dst, rv.Complex(), bitSize), nil
+		}, nil
+	case reflect.Bool:
+		return func(dst []byte, rv reflect.Value) ([]byte, error) {
+			return strconv.AppendBool(dst, rv.Bool()), nil
+		}, nil
+	}
+
+	err := fmt.Errorf("unsupported type %s", typ)
+	return func(dst []byte, rv reflect.Value) ([]byte, error) {
+		return nil, err
+	}, err
+}
+
+// listAppendFunc joins elements of slice or array with separator
+func (c *TextCodec) listAppendFunc(elem *TypeCodec) appendFunc {
+	return func(dst []byte, rv reflect.Value) ([]byte, error) {
+		n := 0
+		for i := 0; i < rv.Len(); i++ {
+			ev := rv.Index(i)
+			if c.skipNull(ev) {
+				continue
+			}
+			if n > 0 {
+				dst = append(dst, c.separator)
+			}
+			n++
+			start := len(dst)
+			d, err := elem.appendValue(dst, ev)
+			if err != nil {
+				return nil, err
+			}
+			dst = c.escapeFrom(d, start)
+		}
+		return dst, nil
+	}
+}
+
+// mapAppendFunc joins entries of map as k=v with separator, sorted by key text
+func (c *TextCodec) mapAppendFunc(key *TypeCodec, elem *TypeCodec) appendFunc {
+	return func(dst []byte, rv reflect.Value) ([]byte, error) {
+		entries := make([][2][]byte, 0, rv.Len())
+
+		iter := rv.MapRange()
+		for iter.Next() {
+			if c.skipNull(iter.Value()) {
+				continue
+			}
+			k, err := key.marshalValue(c, iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			v, err := elem.marshalValue(c, iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, [2][]byte{k, v})
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i][0], entries[j][0]) < 0
+		})
+
+		for i, e := range entries {
+			if i > 0 {
+				dst = append(dst, c.separator)
+			}
+			start := len(dst)
+			dst = c.escapeFrom(append(dst, e[0]...), start)
+			dst = append(dst, c.keyValueSeparator)
+			start = len(dst)
+			dst = c.escapeFrom(append(dst, e[1]...), start)
+		}
+
+		return dst, nil
+	}
+}
+
+type unmarshalFunc func(rv reflect.Value, data []byte) error
+
+// unmarshalFuncOf returns unmarshalFunc of non-pointer typ, values should be addressable.
+// Conversion is checked in order of registered codec, time.Time, time.Duration, encoding.TextUnmarshaler,
+// encoding.BinaryUnmarshaler, registered parse func, and at last kind of typ.
+// Values of unsupported kinds are left unchanged.
+func (c *TextCodec) unmarshalFuncOf(typ reflect.Type, elemCodec func(t reflect.Type) *TypeCodec) unmarshalFunc {
+	var method func(v any, data []byte) error
+
+	registered, isRegistered := c.registry.codec(typ)
+	ptrType := reflect.PtrTo(typ)
+
+	switch {
+	case isRegistered:
+		method = registered.unmarshal
+	case typ == timeType:
+		method = func(v any, data []byte) error {
+			t, err := time.Parse(c.timeLayout, string(data))
+			if err != nil {
+				return err
+			}
+			*(v.(*time.Time)) = t
+			return nil
+		}
+	case typ == durationType:
+		method = func(v any, data []byte) error {
+			d, err := time.ParseDuration(string(data))
+			if err != nil {
+				return err
+			}
+			*(v.(*time.Duration)) = d
+			return nil
+		}
+	case ptrType.Implements(textUnmarshalerType):
+		method = func(v any, data []byte) error {
+			return v.(encoding.TextUnmarshaler).UnmarshalText(data)
+		}
+	case ptrType.Implements(binaryUnmarshalerType):
+		method = func(v any, data []byte) error {
+			d, err := c.bytesEncoding.decode(data)
+			if err != nil {
+				return err
+			}
+			return v.(encoding.BinaryUnmarshaler).UnmarshalBinary(d)
+		}
+	default:
+		if registered, ok := c.registry.parser(typ); ok {
+			method = registered.unmarshal
+		}
+	}
+
+	kindUnmarshal := c.kindUnmarshalFuncOf(typ, elemCodec)
+
+	if method == nil {
+		return kindUnmarshal
+	}
+
+	return func(rv reflect.Value, data []byte) error {
+		pv := rv.Addr()
+		if !pv.CanInterface() {
+			return kindUnmarshal(rv, data)
+		}
+		if err := method(pv.Interface(), data); err != nil {
+			return newUnmarshalTextError(typ, data, err)
+		}
+		return nil
+	}
+}
+
+func (c *TextCodec) kindUnmarshalFuncOf(typ reflect.Type, elemCodec func(t reflect.Type) *TypeCodec) unmarshalFunc {
+	switch typ.Kind() {
+	case reflect.Slice:
+		if et := typ.Elem(); et.PkgPath() == "" && et.Kind() == reflect.Uint8 {
+			return func(rv reflect.Value, data []byte) error {
+				d, err := c.bytesEncoding.decode(data)
+				if err != nil {
+					return newUnmarshalTextError(typ, data, err)
+				}
+				rv.SetBytes(d)
+				return nil
+			}
+		}
+		return c.listUnmarshalFunc(elemCodec(typ.Elem()))
+	case reflect.Array:
+		return c.listUnmarshalFunc(elemCodec(typ.Elem()))
+	case reflect.Map:
+		return c.mapUnmarshalFunc(elemCodec(typ.Key()), elemCodec(typ.Elem()))
+	case reflect.Struct:
+		// fields are nil with the error of appendFuncOf, then values are left unchanged
+		fields, _ := c.textFieldsOf(typ, elemCodec)
+		return c.structUnmarshalFunc(fields)
+	case reflect.String:
+		return func(rv reflect.Value, data []byte) error {
+			rv.SetString(string(data))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bitSize := typ.Bits()
+		return func(rv reflect.Value, data []byte) error {
+			i, err := c.parseInt(data, bitSize)
+			if err != nil {
+				return newUnmarshalTextError(typ, data, err)
+			}
+			rv.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bitSize := typ.Bits()
+		return func(rv reflect.Value, data []byte) error {
+			u, err := c.parseUint(data, bitSize)
+			if err != nil {
+				return newUnmarshalTextError(typ, data, err)
+			}
+			rv.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bitSize := typ.Bits()
+		return func(rv reflect.Value, data []byte) error {
+			f, err := strconv.ParseFloat(string(data), bitSize)
+			if err != nil {
+				return newUnmarshalTextError(typ, data, err)
+			}
+			rv.SetFloat(f)
+			return nil
+		}
+	case reflect.Complex64, reflect.Complex128:
+		bitSize := typ.Bits()
+		return func(rv reflect.Value, data []byte) error {
+			cv, err := strconv.ParseComplex(string(data), bitSize)
+			if err != nil {
+				return newUnmarshalTextError(typ, data, err)
+			}
+			rv.SetComplex(cv)
+			return nil
+		}
+	case reflect.Bool:
+		return func(rv reflect.Value, data []byte) error {
+			b, err := c.parseBool(data)
+			if err != nil {
+				return newUnmarshalTextError(typ, data, err)
+			}
+			rv.SetBool(b)
+			return nil
+		}
+	}
+
+	return func(rv reflect.Value, data []byte) error {
+		return nil
+	}
+}
+
+func (c *TextCodec) listUnmarshalFunc(elem *TypeCodec) unmarshalFunc {
+	return func(rv reflect.Value, data []byte) error {
+		parts := c.split(data, c.separator)
+
+		switch rv.Kind() {
+		case reflect.Slice:
+			rv.Set(reflect.MakeSlice(rv.Type(), len(parts), len(parts)))
+		case reflect.Array:
+			if len(parts) > rv.Len() {
+				return newUnmarshalTextError(rv.Type(), data, fmt.Errorf("got %d elements, but array length is %d", len(parts), rv.Len()))
+			}
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+
+		for i := range parts {
+			if err := elem.unmarshalValue(rv.Index(i), c.unescape(parts[i])); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func (c *TextCodec) mapUnmarshalFunc(key *TypeCodec, elem *TypeCodec) unmarshalFunc {
+	return func(rv reflect.Value, data []byte) error {
+		m := reflect.MakeMap(rv.Type())
+
+		err := c.eachEntry(data, func(k []byte, v []byte) error {
+			kv := reflect.New(rv.Type().Key()).Elem()
+			if err := key.unmarshalValue(kv, k); err != nil {
+				return err
+			}
+
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := elem.unmarshalValue(ev, v); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(kv, ev)
+			return nil
+		})
+		if err != nil {
+			if _, ok := err.(*UnmarshalTextError); !ok {
+				err = newUnmarshalTextError(rv.Type(), data, err)
+			}
+			return err
+		}
+
+		rv.Set(m)
+		return nil
+	}
+}
+
+// textField is field of struct converted as k=v entry
+type textField struct {
+	name      string
+	index     []int
+	codec     *TypeCodec
+	omitempty bool
+}
+
+// textFieldsOf returns fields of struct typ named by TagJSON, sorted by name like keys of map.
+// embedded structs are inlined, and options of each field are overridden by its `text` tag.
+func (c *TextCodec) textFieldsOf(typ reflect.Type, elemCodec func(t reflect.Type) *TypeCodec) ([]*textField, error) {
+	fields := make([]*textField, 0)
+
+	var err error
+
+	walkStructFields(typ, TagJSON, nil, func(field types.StructField, fieldDisplayName string, omitempty bool, index []int) bool {
+		fieldType := field.(*types.RStructField).StructField.Type
+
+		fc, e := c.ForField(field.Tag())
+		if e != nil {
+			err = pkgerrors.Wrapf(e, "%s", fieldDisplayName)
+			return false
+		}
+
+		f := &textField{name: fieldDisplayName, index: index, omitempty: omitempty}
+		if fc == c {
+			f.codec = elemCodec(fieldType)
+		} else {
+			f.codec = fc.codecFor(fieldType)
+		}
+		if f.codec.err != nil {
+			err = pkgerrors.Wrapf(f.codec.err, "%s", fieldDisplayName)
+			return false
+		}
+
+		fields = append(fields, f)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+
+	for i := 1; i < len(fields); i++ {
+		if fields[i].name == fields[i-1].name {
+			return nil, fmt.Errorf("duplicated field name %q of %s", fields[i].name, typ)
+		}
+	}
+
+	return fields, nil
+}
+
+// structAppendFunc joins fields of struct as k=v with separator, same as map of field names.
+// omitempty fields with empty values and fields under nil embedded pointers are skipped.
+func (c *TextCodec) structAppendFunc(fields []*textField) appendFunc {
+	return func(dst []byte, rv reflect.Value) ([]byte, error) {
+		n := 0
+		for _, f := range fields {
+			fv, ok := fieldByIndex(rv, f.index, false)
+			if !ok || (f.omitempty && reflectx.IsEmptyValue(fv)) || c.skipNull(fv) {
+				continue
+			}
+			if n > 0 {
+				dst = append(dst, c.separator)
+			}
+			n++
+			start := len(dst)
+			dst = c.escapeFrom(append(dst, f.name...), start)
+			dst = append(dst, c.keyValueSeparator)
+			start = len(dst)
+			d, err := f.codec.appendValue(dst, fv)
+			if err != nil {
+				return nil, err
+			}
+			dst = c.escapeFrom(d, start)
+		}
+		return dst, nil
+	}
+}
+
+// structUnmarshalFunc decodes k=v entries into fields of struct by name, unknown keys are ignored.
+// nil embedded pointers are allocated for fields under them.
+func (c *TextCodec) structUnmarshalFunc(fields []*textField) unmarshalFunc {
+	byName := make(map[string]*textField, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	return func(rv reflect.Value, data []byte) error {
+		err := c.eachEntry(data, func(k []byte, v []byte) error {
+			f, ok := byName[string(k)]
+			if !ok {
+				return nil
+			}
+			fv, ok := fieldByIndex(rv, f.index, true)
+			if !ok {
+				return nil
+			}
+			return f.codec.unmarshalValue(fv, v)
+		})
+		if err != nil {
+			if _, ok := err.(*UnmarshalTextError); !ok {
+				err = newUnmarshalTextError(rv.Type(), data, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
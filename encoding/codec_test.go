@@ -0,0 +1,234 @@
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type CodecRow struct {
+	Name     string
+	Count    int
+	Ratio    float64
+	Enabled  bool
+	Timeout  time.Duration
+	Duration Duration
+	Tags     []string
+	PtrInt   *int
+	Labels   map[string]int
+}
+
+var codecRow = CodecRow{
+	Name:     "row",
+	Count:    42,
+	Ratio:    0.5,
+	Enabled:  true,
+	Timeout:  time.Second,
+	Duration: Duration(time.Minute),
+	Tags:     []string{"a", "b"},
+	PtrInt:   ptr.Int(1),
+	Labels:   map[string]int{"x": 1},
+}
+
+// PtrText has text methods of pointer receiver only
+type PtrText struct {
+	V string
+}
+
+func (p *PtrText) MarshalText() ([]byte, error) {
+	return []byte("ptr:" + p.V), nil
+}
+
+func (p *PtrText) UnmarshalText(data []byte) error {
+	p.V = string(data)
+	return nil
+}
+
+type RecursiveList []RecursiveList
+
+func TestCodecFor(t *testing.T) {
+	t.Run("same as MarshalText and UnmarshalText", func(t *testing.T) {
+		rv := reflect.ValueOf(&codecRow).Elem()
+
+		decoded := CodecRow{}
+		drv := reflect.ValueOf(&decoded).Elem()
+
+		for i := 0; i < rv.NumField(); i++ {
+			tc, err := CodecFor(rv.Field(i).Type())
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(tc.Type()).To(Equal(rv.Field(i).Type()))
+
+			text, err := tc.AppendValue(nil, rv.Field(i))
+			NewWithT(t).Expect(err).To(BeNil())
+
+			expect, err := MarshalText(rv.Field(i))
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(string(expect)))
+
+			NewWithT(t).Expect(tc.UnmarshalValue(drv.Field(i), text)).To(BeNil())
+		}
+
+		NewWithT(t).Expect(decoded).To(Equal(codecRow))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		tc1, _ := CodecFor(reflect.TypeOf(codecRow))
+		tc2, _ := CodecFor(reflect.TypeOf(codecRow))
+		NewWithT(t).Expect(tc1 == tc2).To(BeTrue())
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		tc, err := CodecFor(reflect.TypeOf(0))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		i := 0
+		NewWithT(t).Expect(tc.UnmarshalPointer(unsafe.Pointer(&i), []byte("12"))).To(BeNil())
+		NewWithT(t).Expect(i).To(Equal(12))
+
+		text, err := tc.AppendPointer([]byte("i="), unsafe.Pointer(&i))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("i=12"))
+	})
+
+	t.Run("pointer receiver methods for addressable values", func(t *testing.T) {
+		v := struct{ P PtrText }{P: PtrText{V: "x"}}
+
+		text, err := MarshalText(reflect.ValueOf(&v).Elem().Field(0))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("ptr:x"))
+
+		text, err = MarshalText(&v.P)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("ptr:x"))
+
+		// not addressable, converted field by field
+		text, err = MarshalText(v.P)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("V=x"))
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		tc, err := CodecFor(reflect.TypeOf(RecursiveList{}))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		text, err := tc.AppendValue(nil, reflect.ValueOf(RecursiveList{{}, {}}))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal(","))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := CodecFor(reflect.TypeOf(make(chan int)))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+
+		_, err = CodecFor(reflect.TypeOf([]func(){}))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("dropped when registered", func(t *testing.T) {
+		r := NewTextCodecRegistry()
+		c := NewTextCodec(WithRegistry(r))
+
+		text, err := c.MarshalText(UUID{1, 2, 3, 4})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1,2,3,4"))
+
+		RegisterTextCodecTo(r, marshalUUID, unmarshalUUID)
+
+		text, err = c.MarshalText(UUID{1, 2, 3, 4})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("01020304"))
+	})
+}
+
+func BenchmarkStructFields(b *testing.B) {
+	rv := reflect.ValueOf(&codecRow).Elem()
+
+	codecs := make([]*TypeCodec, rv.NumField())
+	for i := range codecs {
+		codecs[i], _ = CodecFor(rv.Field(i).Type())
+	}
+
+	texts := make([][]byte, rv.NumField())
+	for i := range texts {
+		texts[i], _ = MarshalText(rv.Field(i))
+	}
+
+	decoded := CodecRow{}
+	drv := reflect.ValueOf(&decoded).Elem()
+
+	b.Run("AppendText", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 64)
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < rv.NumField(); j++ {
+				buf, _ = AppendText(buf[:0], rv.Field(j))
+			}
+		}
+	})
+
+	b.Run("TypeCodec.AppendValue", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 64)
+		for i := 0; i < b.N; i++ {
+			for j, tc := range codecs {
+				buf, _ = tc.AppendValue(buf[:0], rv.Field(j))
+			}
+		}
+	})
+
+	b.Run("TypeCodec.AppendPointer", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 64)
+		for i := 0; i < b.N; i++ {
+			for j, tc := range codecs {
+				buf, _ = tc.AppendPointer(buf[:0], unsafe.Pointer(rv.Field(j).UnsafeAddr()))
+			}
+		}
+	})
+
+	b.Run("UnmarshalText", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range texts {
+				_ = UnmarshalText(drv.Field(j), texts[j])
+			}
+		}
+	})
+
+	b.Run("TypeCodec.UnmarshalValue", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j, tc := range codecs {
+				_ = tc.UnmarshalValue(drv.Field(j), texts[j])
+			}
+		}
+	})
+}
+
+func ExampleCodecFor() {
+	type Point struct {
+		X, Y int
+	}
+
+	tc, _ := CodecFor(reflect.TypeOf(0))
+
+	p := Point{X: 1, Y: 2}
+	rv := reflect.ValueOf(&p).Elem()
+
+	line := make([]byte, 0)
+	for i := 0; i < rv.NumField(); i++ {
+		if i > 0 {
+			line = append(line, ' ')
+		}
+		line, _ = tc.AppendValue(line, rv.Field(i))
+	}
+
+	fmt.Println(string(line))
+	// Output:
+	// 1 2
+}
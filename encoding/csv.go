@@ -0,0 +1,304 @@
+package encoding
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+)
+
+// TagCSV is the struct tag for CSV column names.
+//
+//	Amount float64 `csv:"amount,required"`
+const TagCSV = "csv"
+
+// csvRowType returns struct type of rows in typ, which could be struct or list of structs
+func csvRowType(typ reflect.Type) (reflect.Type, bool) {
+	typ = reflectx.Deref(typ)
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = reflectx.Deref(typ.Elem())
+	}
+	return typ, typ.Kind() == reflect.Struct
+}
+
+// CSVEncoder writes structs as CSV rows, with header row of columns named by the `csv` tag.
+// Each cell is converted by MarshalText, and nested structs are flattened as `a.b` columns.
+type CSVEncoder struct {
+	w       *csv.Writer
+	typ     reflect.Type
+	columns []*structColumn
+}
+
+func NewCSVEncoder(w io.Writer) *CSVEncoder {
+	return &CSVEncoder{w: csv.NewWriter(w)}
+}
+
+// Encode writes v as rows, v could be struct or slice of structs.
+// Header row is written before the first row, and all rows should be of same type.
+// Rows are flushed before return, including rows written before the failed one.
+func (e *CSVEncoder) Encode(v any) (err error) {
+	defer func() {
+		if flushErr := e.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
+
+	rv := reflect.ValueOf(v)
+
+	typ, ok := csvRowType(rv.Type())
+	if !ok {
+		return pkgerrors.Errorf("csv encode need struct or slice of structs, but got %T", v)
+	}
+
+	if err := e.writeHeader(typ); err != nil {
+		return err
+	}
+
+	rv = reflectx.Indirect(rv)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.writeRow(rv.Index(i)); err != nil {
+				return pkgerrors.Wrapf(err, "csv encode row %d", i)
+			}
+		}
+	case reflect.Struct:
+		if err := e.writeRow(rv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush writes buffered rows to the underlying io.Writer, and returns error of any previous write or flush,
+// like csv.Writer.Flush and csv.Writer.Error.
+func (e *CSVEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *CSVEncoder) writeHeader(typ reflect.Type) error {
+	if e.typ != nil {
+		if e.typ != typ {
+			return pkgerrors.Errorf("csv encode rows of %s, but got %s", e.typ, typ)
+		}
+		return nil
+	}
+
+	columns, err := structColumnsOf(typ, TagCSV, "", nil)
+	if err != nil {
+		return err
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := e.w.Write(header); err != nil {
+		return err
+	}
+
+	e.typ = typ
+	e.columns = columns
+	return nil
+}
+
+func (e *CSVEncoder) writeRow(rv reflect.Value) error {
+	if isNil(rv) {
+		return pkgerrors.New("nil row")
+	}
+	rv = reflectx.Indirect(rv)
+
+	record := make([]string, len(e.columns))
+
+	for i, c := range e.columns {
+		fv, ok := c.value(rv, false)
+		if !ok {
+			record[i] = string(c.tc.appendNull(nil))
+			continue
+		}
+		text, err := c.codec.marshalValue(c.tc, fv)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "csv column %s", c.name)
+		}
+		record[i] = string(text)
+	}
+
+	return e.w.Write(record)
+}
+
+// CSVDecoder reads CSV rows into structs, columns of header row are mapped to fields by the `csv` tag.
+// Columns could be in any order, unknown columns are ignored,
+// and missing columns are only errors when fields tagged with `required`.
+// Each non-empty cell is converted by UnmarshalText, empty cells are left as zero values.
+type CSVDecoder struct {
+	r      *csv.Reader
+	header []string
+
+	typ reflect.Type
+	// columns by index of header, nil when no field of the column
+	columns []*structColumn
+}
+
+func NewCSVDecoder(r io.Reader) *CSVDecoder {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return &CSVDecoder{r: cr}
+}
+
+// Header returns header row, which is read on first call
+func (d *CSVDecoder) Header() ([]string, error) {
+	if d.header != nil {
+		return d.header, nil
+	}
+
+	header, err := d.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	d.header = append([]string(nil), header...)
+	if len(d.header) > 0 {
+		d.header[0] = strings.TrimPrefix(d.header[0], "\ufeff")
+	}
+	return d.header, nil
+}
+
+// Decode reads next row into struct v, or all rest rows appended to slice v,
+// or rows into elements of array v until it is full, and elements without rows are zeroed.
+// io.EOF is returned when no more rows for struct v.
+// Errors of converting cells are *CSVError with line and column,
+// the row is consumed, so next Decode continues with next row.
+func (d *CSVDecoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("csv decode need non-nil ptr value, but got %T", v)
+	}
+
+	typ, ok := csvRowType(rv.Type())
+	if !ok {
+		return pkgerrors.Errorf("csv decode need struct or slice of structs, but got %T", v)
+	}
+
+	if err := d.bind(typ); err != nil {
+		return err
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return d.readRow(rv)
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := d.readRow(rv.Index(i)); err != nil {
+				if err == io.EOF {
+					for ; i < rv.Len(); i++ {
+						rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+					}
+					return nil
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	for {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := d.readRow(elem); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		rv.Set(reflect.Append(rv, elem))
+	}
+}
+
+// EachCSVRow reads rest rows of d into new values of T one by one, and calls each with them.
+func EachCSVRow[T any](d *CSVDecoder, each func(row *T) error) error {
+	for {
+		row := new(T)
+		if err := d.Decode(row); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := each(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *CSVDecoder) bind(typ reflect.Type) error {
+	if d.typ == typ {
+		return nil
+	}
+
+	header, err := d.Header()
+	if err != nil {
+		return err
+	}
+
+	columns, err := structColumnsOf(typ, TagCSV, "", nil)
+	if err != nil {
+		return err
+	}
+
+	d.columns = make([]*structColumn, len(header))
+
+	for _, c := range columns {
+		found := false
+		for i, name := range header {
+			if name == c.name {
+				d.columns[i] = c
+				found = true
+			}
+		}
+		if !found && c.required {
+			return pkgerrors.Errorf("missing required csv column %s", c.name)
+		}
+	}
+
+	d.typ = typ
+	return nil
+}
+
+func (d *CSVDecoder) readRow(rv reflect.Value) error {
+	record, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		rv.Set(reflectx.New(rv.Type()))
+	}
+	rv = reflectx.Indirect(rv)
+
+	for i, cell := range record {
+		if i >= len(d.columns) || d.columns[i] == nil || cell == "" {
+			continue
+		}
+
+		c := d.columns[i]
+		fv, _ := c.value(rv, true)
+
+		if err := c.codec.unmarshalValue(fv, []byte(cell)); err != nil {
+			line, _ := d.r.FieldPos(i)
+			return &CSVError{Line: line, Column: i + 1, Name: c.name, Err: err}
+		}
+	}
+	return nil
+}
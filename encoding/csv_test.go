@@ -0,0 +1,158 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type CSVAddress struct {
+	City string `csv:"city"`
+}
+
+type CSVReport struct {
+	ID      int           `csv:"id,required"`
+	Name    string        `csv:"name"`
+	Amount  *float64      `csv:"amount"`
+	Tags    []string      `csv:"tags"`
+	Elapsed time.Duration `csv:"elapsed"`
+	Address *CSVAddress   `csv:"address"`
+	Ignored string        `csv:"-"`
+}
+
+func TestCSVEncoder(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewCSVEncoder(buf)
+
+	NewWithT(t).Expect(e.Encode([]CSVReport{
+		{ID: 1, Name: "a, b", Amount: ptr.Float64(1.5), Tags: []string{"x", "y"}, Elapsed: time.Second, Address: &CSVAddress{City: "Paris"}},
+		{ID: 2, Name: "say \"hi\""},
+	})).To(BeNil())
+	NewWithT(t).Expect(e.Encode(&CSVReport{ID: 3})).To(BeNil())
+
+	NewWithT(t).Expect(buf.String()).To(Equal(`id,name,amount,tags,elapsed,address.city
+1,"a, b",1.5,"x,y",1s,Paris
+2,"say ""hi""",,,0s,
+3,,,,0s,
+`))
+
+	NewWithT(t).Expect(e.Encode(CSVAddress{})).NotTo(BeNil())
+	NewWithT(t).Expect(NewCSVEncoder(buf).Encode(1)).NotTo(BeNil())
+
+	t.Run("rows before error are flushed", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		e := NewCSVEncoder(buf)
+
+		NewWithT(t).Expect(e.Encode([]*CSVAddress{{City: "Paris"}, nil})).NotTo(BeNil())
+		NewWithT(t).Expect(buf.String()).To(Equal("city\nParis\n"))
+		NewWithT(t).Expect(e.Flush()).To(BeNil())
+	})
+
+	t.Run("invalid text tag of embedded field", func(t *testing.T) {
+		type Embedded struct {
+			Mode int `csv:"mode" text:"base=3"`
+		}
+		type Row struct {
+			Embedded
+			Name string `csv:"name"`
+		}
+		NewWithT(t).Expect(NewCSVEncoder(bytes.NewBuffer(nil)).Encode(Row{})).NotTo(BeNil())
+	})
+}
+
+const csvReports = "\ufeffaddress.city,unknown,amount,name,id\n" +
+	"Paris,?,1.5,\"a, b\",1\n" +
+	",,,b,2\n" +
+	"Rome,,x,c,3\n" +
+	",,,d,4\n"
+
+func TestCSVDecoder(t *testing.T) {
+	t.Run("into slice", func(t *testing.T) {
+		d := NewCSVDecoder(strings.NewReader(csvReports[:strings.Index(csvReports, "Rome")]))
+
+		list := make([]CSVReport, 0)
+		NewWithT(t).Expect(d.Decode(&list)).To(BeNil())
+		NewWithT(t).Expect(list).To(Equal([]CSVReport{
+			{ID: 1, Name: "a, b", Amount: ptr.Float64(1.5), Address: &CSVAddress{City: "Paris"}},
+			{ID: 2, Name: "b"},
+		}))
+
+		header, err := d.Header()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(header).To(Equal([]string{"address.city", "unknown", "amount", "name", "id"}))
+	})
+
+	t.Run("row by row with error", func(t *testing.T) {
+		d := NewCSVDecoder(strings.NewReader(csvReports))
+
+		ids := make([]int, 0)
+		var csvErr *CSVError
+
+		for {
+			r := &CSVReport{}
+			err := d.Decode(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				NewWithT(t).Expect(errors.As(err, &csvErr)).To(BeTrue())
+				continue
+			}
+			ids = append(ids, r.ID)
+		}
+
+		NewWithT(t).Expect(ids).To(Equal([]int{1, 2, 4}))
+		NewWithT(t).Expect(csvErr.Line).To(Equal(4))
+		NewWithT(t).Expect(csvErr.Column).To(Equal(3))
+		NewWithT(t).Expect(csvErr.Name).To(Equal("amount"))
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		d := NewCSVDecoder(strings.NewReader("name,id\na,1\nb,2\n"))
+
+		names := make([]string, 0)
+		err := EachCSVRow(d, func(r *CSVReport) error {
+			names = append(names, r.Name)
+			return nil
+		})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(names).To(Equal([]string{"a", "b"}))
+	})
+
+	t.Run("into array", func(t *testing.T) {
+		d := NewCSVDecoder(strings.NewReader(csvReports[:strings.Index(csvReports, "Rome")]))
+
+		first := [1]CSVReport{}
+		NewWithT(t).Expect(d.Decode(&first)).To(BeNil())
+		NewWithT(t).Expect(first[0].ID).To(Equal(1))
+
+		rest := [2]CSVReport{{ID: 9}, {ID: 9}}
+		NewWithT(t).Expect(d.Decode(&rest)).To(BeNil())
+		NewWithT(t).Expect(rest).To(Equal([2]CSVReport{{ID: 2, Name: "b"}, {}}))
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		d := NewCSVDecoder(strings.NewReader("name\na\n"))
+		NewWithT(t).Expect(d.Decode(&CSVReport{})).NotTo(BeNil())
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		list := []*CSVReport{
+			{ID: 1, Name: "a", Amount: ptr.Float64(2), Tags: []string{"x", "y"}, Elapsed: time.Minute, Address: &CSVAddress{City: "Paris"}},
+			{ID: 2, Name: "line\nbreak", Elapsed: time.Second},
+		}
+
+		buf := bytes.NewBuffer(nil)
+		NewWithT(t).Expect(NewCSVEncoder(buf).Encode(list)).To(BeNil())
+
+		decoded := make([]*CSVReport, 0)
+		NewWithT(t).Expect(NewCSVDecoder(buf).Decode(&decoded)).To(BeNil())
+		NewWithT(t).Expect(decoded).To(Equal(list))
+	})
+}
@@ -0,0 +1,82 @@
+package encoding
+
+import (
+	"reflect"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagDefault is the struct tag for default value of field
+const TagDefault = "default"
+
+// ApplyDefaults sets the `default` tag value into each empty field of struct v, fields are named by tag.
+// Nested structs are walked, nil ptr of them are only allocated when any default exists below.
+// Paths of defaulted fields like `db.port` are returned.
+func ApplyDefaults(v any, tag string) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, pkgerrors.Errorf("apply defaults need non-nil ptr value, but got %T", v)
+	}
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return nil, pkgerrors.Errorf("apply defaults need struct value, but got %T", v)
+	}
+
+	return applyDefaults(rv, rv.Type(), tag, "", nil)
+}
+
+// applyDefaults applies defaults to fields of struct typ, which is at index of root.
+// fields under nil ptr structs are empty, the ptrs are allocated when the field has default.
+func applyDefaults(root reflect.Value, typ reflect.Type, tag string, prefix string, index []int) (defaulted []string, err error) {
+	walkStructFields(typ, tag, index, func(field types.StructField, name string, omitempty bool, fieldIndex []int) bool {
+		fieldType := field.(*types.RStructField).StructField.Type
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if DefaultTextCodec.isNestedStruct(fieldType) {
+			d, e := applyDefaults(root, reflectx.Deref(fieldType), tag, name, fieldIndex)
+			if e != nil {
+				err = e
+				return false
+			}
+			defaulted = append(defaulted, d...)
+			return true
+		}
+
+		value, ok := field.Tag().Lookup(TagDefault)
+		if !ok {
+			return true
+		}
+
+		fv, ok := fieldByIndex(root, fieldIndex, false)
+		if ok && !reflectx.IsEmptyValue(fv) {
+			return true
+		}
+		if !ok {
+			fv, _ = fieldByIndex(root, fieldIndex, true)
+		}
+
+		tc, e := DefaultTextCodec.ForField(field.Tag())
+		if e != nil {
+			err = pkgerrors.Wrapf(e, "default of %s", name)
+			return false
+		}
+
+		if e := tc.UnmarshalText(fv, []byte(value)); e != nil {
+			err = pkgerrors.Wrapf(e, "default of %s", name)
+			return false
+		}
+
+		defaulted = append(defaulted, name)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return defaulted, nil
+}
@@ -0,0 +1,97 @@
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type DefaultDB struct {
+	Host string `json:"host" default:"localhost"`
+	Port int    `json:"port" default:"5432"`
+}
+
+type DefaultTLS struct {
+	Cert string `json:"cert"`
+}
+
+type DefaultLog struct {
+	Level string `json:"level" default:"info"`
+}
+
+type DefaultConfig struct {
+	DefaultLog
+	Name    string        `json:"name"`
+	Timeout time.Duration `json:"timeout" default:"30s"`
+	Retries *int          `json:"retries" default:"3"`
+	Hosts   []string      `json:"hosts" default:"a,b"`
+	Debug   bool          `json:"debug" default:"true"`
+	DB      DefaultDB     `json:"db"`
+	Replica *DefaultDB    `json:"replica"`
+	TLS     *DefaultTLS   `json:"tls"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	c := DefaultConfig{
+		Name: "srv",
+		DB:   DefaultDB{Port: 3306},
+	}
+
+	defaulted, err := ApplyDefaults(&c, "json")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(DefaultConfig{
+		DefaultLog: DefaultLog{Level: "info"},
+		Name:       "srv",
+		Timeout:    30 * time.Second,
+		Retries:    ptr.Int(3),
+		Hosts:      []string{"a", "b"},
+		Debug:      true,
+		DB:         DefaultDB{Host: "localhost", Port: 3306},
+		Replica:    &DefaultDB{Host: "localhost", Port: 5432},
+	}))
+	NewWithT(t).Expect(defaulted).To(Equal([]string{
+		"level",
+		"timeout",
+		"retries",
+		"hosts",
+		"debug",
+		"db.host",
+		"replica.host",
+		"replica.port",
+	}))
+
+	t.Run("no empty fields", func(t *testing.T) {
+		defaulted, err := ApplyDefaults(&c, "json")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(defaulted).To(BeEmpty())
+	})
+
+	t.Run("embedded ptr", func(t *testing.T) {
+		v := struct {
+			*DefaultTLS
+			*DefaultLog
+			Y int `default:"3"`
+		}{}
+
+		defaulted, err := ApplyDefaults(&v, "json")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(defaulted).To(Equal([]string{"level", "Y"}))
+		NewWithT(t).Expect(v.DefaultTLS).To(BeNil())
+		NewWithT(t).Expect(v.DefaultLog).To(Equal(&DefaultLog{Level: "info"}))
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		v := struct {
+			Port int `default:"x"`
+		}{}
+		_, err := ApplyDefaults(&v, "json")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("not ptr", func(t *testing.T) {
+		_, err := ApplyDefaults(DefaultConfig{}, "json")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,237 @@
+package encoding
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DotenvReader reads key/value pairs from .env file.
+//
+//	# comment
+//	export HOST=localhost
+//	NAME="multi
+//	line\tvalue" # comment
+//	PATH='literal $PATH'
+//	LIST=a,\
+//	  b
+type DotenvReader struct {
+	l *lineReader
+}
+
+func NewDotenvReader(r io.Reader) *DotenvReader {
+	return &DotenvReader{l: newLineReader(r)}
+}
+
+// Read returns next key/value pair, io.EOF when no more pairs
+func (r *DotenvReader) Read() (key string, value string, err error) {
+	for {
+		c, err := parseDotenvChunk(r.l)
+		if err != nil {
+			return "", "", err
+		}
+		if c.entry {
+			return c.key, c.value, nil
+		}
+	}
+}
+
+// ReadDotenvFile reads .env file as KeyValueFile, which keeps comments and order of keys
+func ReadDotenvFile(r io.Reader) (*KeyValueFile, error) {
+	return readKeyValueFile(r, parseDotenvChunk, formatDotenv)
+}
+
+// UnmarshalDotenv fills struct v from .env file, like UnmarshalEnv
+func UnmarshalDotenv(r io.Reader, v any, opts ...EnvOption) error {
+	f, err := ReadDotenvFile(r)
+	if err != nil {
+		return err
+	}
+	return UnmarshalEnv(v, append(opts, WithEnvMap(f.Map()))...)
+}
+
+// DotenvWriter writes key/value pairs as .env file
+type DotenvWriter struct {
+	w io.Writer
+}
+
+func NewDotenvWriter(w io.Writer) *DotenvWriter {
+	return &DotenvWriter{w: w}
+}
+
+// WriteComment writes each line of comment with '#'
+func (w *DotenvWriter) WriteComment(comment string) error {
+	return writeComment(w.w, "# ", comment)
+}
+
+// Write writes KEY=value line, value quoted when needed
+func (w *DotenvWriter) Write(key string, value string) error {
+	_, err := io.WriteString(w.w, formatDotenv(key, value)+"\n")
+	return err
+}
+
+func writeComment(w io.Writer, prefix string, comment string) error {
+	for _, line := range strings.Split(comment, "\n") {
+		if _, err := io.WriteString(w, strings.TrimRight(prefix+line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatDotenv(key string, value string) string {
+	return key + "=" + quoteEnvValue(value)
+}
+
+func parseDotenvChunk(l *lineReader) (*kvChunk, error) {
+	line, err := l.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	text, eol := cutEOL(line)
+	rest := strings.TrimLeft(text, " \t")
+
+	if rest == "" || rest[0] == '#' {
+		return &kvChunk{raw: line, eol: eol}, nil
+	}
+
+	prefix := text[:len(text)-len(rest)]
+	if s := strings.TrimPrefix(rest, "export"); len(s) < len(rest) && s != "" && (s[0] == ' ' || s[0] == '\t') {
+		s = strings.TrimLeft(s, " \t")
+		prefix += rest[:len(rest)-len(s)]
+		rest = s
+	}
+
+	key, value, ok := strings.Cut(rest, "=")
+	if !ok {
+		return nil, l.errorf("missing '=' in %q", text)
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t\"'") {
+		return nil, l.errorf("invalid key %q", key)
+	}
+
+	c := &kvChunk{entry: true, key: key, prefix: prefix}
+
+	value = strings.TrimLeft(value, " \t")
+
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		q := value[0]
+		content := value[1:]
+
+		// quoted value may span lines
+		for {
+			if end := closingQuote(content, q); end >= 0 {
+				if tail := strings.TrimSpace(content[end+1:]); tail != "" && tail[0] != '#' {
+					return nil, l.errorf("unexpected %q after quoted value", tail)
+				}
+				content = content[:end]
+				break
+			}
+
+			next, err := l.readLine()
+			if err != nil {
+				if err == io.EOF {
+					return nil, l.errorf("unterminated quoted value of %s", key)
+				}
+				return nil, err
+			}
+			line += next
+			next, eol = cutEOL(next)
+			content += "\n" + next
+		}
+
+		if q == '"' {
+			content, err = unquoteDotenv(content)
+			if err != nil {
+				return nil, l.errorf("invalid value of %s: %s", key, err)
+			}
+		}
+
+		c.value = content
+	} else {
+		// trailing '\' of unquoted value continues next line
+		for {
+			value = strings.TrimSpace(stripInlineComment(value, "#"))
+			if !strings.HasSuffix(value, `\`) {
+				break
+			}
+			value = strings.TrimSuffix(value, `\`)
+
+			next, err := l.readLine()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			line += next
+			next, eol = cutEOL(next)
+			value += strings.TrimLeft(next, " \t")
+		}
+
+		c.value = value
+	}
+
+	c.raw = line
+	c.eol = eol
+	return c, nil
+}
+
+// closingQuote returns index of closing quote q in s, escapes are only in double quotes
+func closingQuote(s string, q byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if q == '"' {
+				i++
+			}
+		case q:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripInlineComment strips comment started by any of marks after whitespace
+func stripInlineComment(s string, marks string) string {
+	for i := 1; i < len(s); i++ {
+		if strings.IndexByte(marks, s[i]) >= 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// unquoteDotenv unescapes content of double quotes, escapes are same as Go with `\$` added
+func unquoteDotenv(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	b := make([]byte, 0, len(s))
+
+	for len(s) > 0 {
+		if strings.HasPrefix(s, `\$`) {
+			b = append(b, '$')
+			s = s[2:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(s, '"')
+		if err != nil {
+			return "", err
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			b = append(b, byte(r))
+		} else {
+			b = utf8.AppendRune(b, r)
+		}
+		s = tail
+	}
+
+	return string(b), nil
+}
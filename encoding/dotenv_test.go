@@ -0,0 +1,139 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const dotenvFile = `# service
+export HOST=localhost
+PORT = 8080 # inline comment
+NAME="multi
+line\tvalue"
+LITERAL='$HOME \n'
+HASH=a#b
+LIST=a,\
+  b
+
+EMPTY=
+`
+
+func TestDotenvReader(t *testing.T) {
+	r := NewDotenvReader(strings.NewReader(dotenvFile))
+
+	pairs := make([][2]string, 0)
+	for {
+		k, v, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		NewWithT(t).Expect(err).To(BeNil())
+		pairs = append(pairs, [2]string{k, v})
+	}
+
+	NewWithT(t).Expect(pairs).To(Equal([][2]string{
+		{"HOST", "localhost"},
+		{"PORT", "8080"},
+		{"NAME", "multi\nline\tvalue"},
+		{"LITERAL", `$HOME \n`},
+		{"HASH", "a#b"},
+		{"LIST", "a,b"},
+		{"EMPTY", ""},
+	}))
+
+	t.Run("syntax error", func(t *testing.T) {
+		for input, line := range map[string]int{
+			"A=1\nB\n":         2,
+			"A=1\n\nB=\"x\n":   3,
+			"A=1\nB=\"\\q\"\n": 2,
+			"A=1\nB='x' y\n":   2,
+		} {
+			_, err := ReadDotenvFile(strings.NewReader(input))
+
+			syntaxErr := &SyntaxError{}
+			NewWithT(t).Expect(errors.As(err, &syntaxErr)).To(BeTrue(), input)
+			NewWithT(t).Expect(syntaxErr.Line).To(Equal(line), input)
+		}
+	})
+}
+
+func TestKeyValueFile(t *testing.T) {
+	f, err := ReadDotenvFile(strings.NewReader(dotenvFile))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	NewWithT(t).Expect(f.Keys()).To(Equal([]string{"HOST", "PORT", "NAME", "LITERAL", "HASH", "LIST", "EMPTY"}))
+
+	f.Set("HOST", "example.com")
+	f.Set("LIST", "a b")
+	f.Set("DEBUG", "true")
+
+	host, ok := f.Get("HOST")
+	NewWithT(t).Expect(ok).To(BeTrue())
+	NewWithT(t).Expect(host).To(Equal("example.com"))
+
+	buf := bytes.NewBuffer(nil)
+	_, err = f.WriteTo(buf)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(buf.String()).To(Equal(`# service
+export HOST=example.com
+PORT = 8080 # inline comment
+NAME="multi
+line\tvalue"
+LITERAL='$HOME \n'
+HASH=a#b
+LIST="a b"
+
+EMPTY=
+DEBUG=true
+`))
+
+	f2, err := ReadDotenvFile(bytes.NewReader(buf.Bytes()))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(f2.Map()).To(Equal(map[string]string{
+		"HOST":    "example.com",
+		"PORT":    "8080",
+		"NAME":    "multi\nline\tvalue",
+		"LITERAL": `$HOME \n`,
+		"HASH":    "a#b",
+		"LIST":    "a b",
+		"EMPTY":   "",
+		"DEBUG":   "true",
+	}))
+}
+
+func TestDotenvWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewDotenvWriter(buf)
+
+	NewWithT(t).Expect(w.WriteComment("generated\nby test")).To(BeNil())
+	NewWithT(t).Expect(w.Write("A", "plain")).To(BeNil())
+	NewWithT(t).Expect(w.Write("B", "has space\n\"quoted\" $x")).To(BeNil())
+
+	NewWithT(t).Expect(buf.String()).To(Equal("# generated\n# by test\nA=plain\nB=\"has space\\n\\\"quoted\\\" $x\"\n"))
+
+	f, err := ReadDotenvFile(bytes.NewReader(buf.Bytes()))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(f.Map()).To(Equal(map[string]string{
+		"A": "plain",
+		"B": "has space\n\"quoted\" $x",
+	}))
+}
+
+func TestUnmarshalDotenv(t *testing.T) {
+	v := struct {
+		Host string
+		Port int
+		Name string `env:"NAME"`
+	}{}
+
+	err := UnmarshalDotenv(strings.NewReader(dotenvFile), &v)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(v.Host).To(Equal("localhost"))
+	NewWithT(t).Expect(v.Port).To(Equal(8080))
+	NewWithT(t).Expect(v.Name).To(Equal("multi\nline\tvalue"))
+}
@@ -0,0 +1,102 @@
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Enum maps names onto values of named integer type T.
+// Names are decoded case-insensitively, and errors list all allowed names.
+//
+//	var LevelEnum = encoding.NewEnum(map[Level]string{Debug: "debug", Info: "info"})
+//
+//	func init() {
+//		LevelEnum.Register(encoding.DefaultTextCodecRegistry)
+//	}
+type Enum[T integer] struct {
+	names  map[T]string
+	values []T
+}
+
+// NewEnum creates Enum of names, each value should have unique name
+func NewEnum[T integer](names map[T]string) *Enum[T] {
+	e := &Enum[T]{
+		names:  make(map[T]string, len(names)),
+		values: make([]T, 0, len(names)),
+	}
+
+	for v, name := range names {
+		e.names[v] = name
+		e.values = append(e.values, v)
+	}
+
+	sort.Slice(e.values, func(i, j int) bool {
+		return e.values[i] < e.values[j]
+	})
+
+	return e
+}
+
+// Values returns all values in order
+func (e *Enum[T]) Values() []T {
+	return append([]T(nil), e.values...)
+}
+
+// Names returns names of all values in order
+func (e *Enum[T]) Names() []string {
+	names := make([]string, len(e.values))
+	for i, v := range e.values {
+		names[i] = e.names[v]
+	}
+	return names
+}
+
+// Name returns name of v
+func (e *Enum[T]) Name(v T) (string, bool) {
+	name, ok := e.names[v]
+	return name, ok
+}
+
+// Parse returns value of name
+func (e *Enum[T]) Parse(name string) (T, error) {
+	for _, v := range e.values {
+		if strings.EqualFold(e.names[v], name) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %s %q, should be one of %s", e.typeName(), name, strings.Join(e.Names(), ", "))
+}
+
+// Text returns name of v as text, or error when v has no name
+func (e *Enum[T]) Text(v T) ([]byte, error) {
+	name, ok := e.names[v]
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %d, should be one of %s", e.typeName(), v, strings.Join(e.Names(), ", "))
+	}
+	return []byte(name), nil
+}
+
+// Scan sets v by name in data, like Parse
+func (e *Enum[T]) Scan(v *T, data []byte) error {
+	x, err := e.Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*v = x
+	return nil
+}
+
+// Register registers Enum as text codec of T into r
+func (e *Enum[T]) Register(r *TextCodecRegistry) {
+	RegisterTextCodecTo[T](r, e.Text, e.Scan)
+}
+
+func (e *Enum[T]) typeName() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
@@ -0,0 +1,61 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type Color uint8
+
+const (
+	ColorRed Color = iota + 1
+	ColorGreen
+	ColorBlue
+)
+
+var ColorEnum = NewEnum(map[Color]string{
+	ColorRed:   "red",
+	ColorGreen: "green",
+	ColorBlue:  "blue",
+})
+
+func TestEnum(t *testing.T) {
+	NewWithT(t).Expect(ColorEnum.Names()).To(Equal([]string{"red", "green", "blue"}))
+	NewWithT(t).Expect(ColorEnum.Values()).To(Equal([]Color{ColorRed, ColorGreen, ColorBlue}))
+
+	c, err := ColorEnum.Parse("Green")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(ColorGreen))
+
+	_, err = ColorEnum.Parse("pink")
+	NewWithT(t).Expect(err.Error()).To(Equal(`invalid encoding.Color "pink", should be one of red, green, blue`))
+
+	text, err := ColorEnum.Text(ColorRed)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(text)).To(Equal("red"))
+
+	_, err = ColorEnum.Text(Color(9))
+	NewWithT(t).Expect(err.Error()).To(Equal(`invalid encoding.Color 9, should be one of red, green, blue`))
+
+	NewWithT(t).Expect(ColorEnum.Scan(&c, []byte("BLUE"))).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(ColorBlue))
+
+	t.Run("registered", func(t *testing.T) {
+		r := NewTextCodecRegistry()
+		ColorEnum.Register(r)
+		tc := NewTextCodec(WithRegistry(r))
+
+		text, err := tc.MarshalText([]Color{ColorBlue, ColorRed})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("blue,red"))
+
+		var colors []Color
+		NewWithT(t).Expect(tc.UnmarshalText(&colors, []byte("RED,green"))).To(BeNil())
+		NewWithT(t).Expect(colors).To(Equal([]Color{ColorRed, ColorGreen}))
+
+		err = tc.UnmarshalText(&colors, []byte("red,pink"))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("should be one of red, green, blue"))
+	})
+}
@@ -0,0 +1,223 @@
+package encoding
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagEnv is the struct tag for env var names.
+//
+//	Port int `env:"PORT,required"`
+const TagEnv = "env"
+
+type envOptions struct {
+	prefix string
+	lookup func(key string) (string, bool)
+	text   *TextCodec
+}
+
+type EnvOption func(o *envOptions)
+
+// WithEnvPrefix sets prefix of env var names, joined with '_'
+func WithEnvPrefix(prefix string) EnvOption {
+	return func(o *envOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithEnvLookup sets lookup of env vars. default os.LookupEnv
+func WithEnvLookup(lookup func(key string) (string, bool)) EnvOption {
+	return func(o *envOptions) {
+		o.lookup = lookup
+	}
+}
+
+// WithEnvMap looks up env vars from m
+func WithEnvMap(m map[string]string) EnvOption {
+	return WithEnvLookup(func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	})
+}
+
+// WithEnvTextCodec sets TextCodec to convert env values. default DefaultTextCodec
+func WithEnvTextCodec(c *TextCodec) EnvOption {
+	return func(o *envOptions) {
+		o.text = c
+	}
+}
+
+func newEnvOptions(opts []EnvOption) *envOptions {
+	o := &envOptions{
+		lookup: os.LookupEnv,
+		text:   DefaultTextCodec,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UnmarshalEnv fills struct v from env vars, named by the `env` tag or upper-cased field name.
+// Nested structs are mapped to PREFIX_NESTED_FIELD, embedded structs are inlined.
+// Nested nil ptr structs are set only when any env var of them found,
+// but their required env vars are reported as missing either way.
+func UnmarshalEnv(v any, opts ...EnvOption) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("unmarshal env need non-nil ptr value, but got %T", v)
+	}
+
+	o := newEnvOptions(opts)
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("unmarshal env need struct value, but got %T", v)
+	}
+
+	_, missing, err := o.unmarshalStruct(rv, o.prefix)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return pkgerrors.Errorf("missing required env %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// MarshalEnv encodes struct v as KEY=value lines, like .env file.
+// Nested nil structs are walked as zero values, so it could be used to generate .env templates.
+func MarshalEnv(v any, opts ...EnvOption) ([]byte, error) {
+	o := newEnvOptions(opts)
+
+	rv, ok := indirectStruct(v, false)
+	if !ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && reflectx.Deref(rv.Type()).Kind() == reflect.Struct {
+			return MarshalEnv(reflect.New(reflectx.Deref(rv.Type())).Interface(), opts...)
+		}
+		return nil, pkgerrors.Errorf("marshal env need struct value, but got %T", v)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := o.marshalStruct(buf, rv, o.prefix); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// envKey joins prefix and name of field, name is upper-cased unless it is from the tag.
+func envKey(prefix string, name string, tagged bool) string {
+	if !tagged {
+		name = strings.ToUpper(name)
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func envNameTagged(field types.StructField) bool {
+	return reflectx.StructTag(field.Tag().Get(TagEnv)).Name() != ""
+}
+
+// unmarshalStruct returns whether any env var found, and names of missing required env vars.
+func (o *envOptions) unmarshalStruct(rv reflect.Value, prefix string) (found bool, missing []string, err error) {
+	err = eachStructField(rv, TagEnv, true, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		key := envKey(prefix, name, envNameTagged(field))
+
+		if o.text.isNestedStruct(fv.Type()) {
+			// nil ptr struct only be set when any env var of it found,
+			// but its required env vars are missing either way.
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				nv := reflectx.New(fv.Type())
+				ok, m, err := o.unmarshalStruct(reflectx.Indirect(nv), key)
+				if err != nil {
+					return err
+				}
+				if ok {
+					fv.Set(nv)
+					found = true
+				}
+				missing = append(missing, m...)
+				return nil
+			}
+
+			ok, m, err := o.unmarshalStruct(reflectx.Indirect(fv), key)
+			if err != nil {
+				return err
+			}
+			found = found || ok
+			missing = append(missing, m...)
+			return nil
+		}
+
+		value, ok := o.lookup(key)
+		if !ok {
+			if reflectx.StructTag(field.Tag().Get(TagEnv)).HasFlag("required") {
+				missing = append(missing, key)
+			}
+			return nil
+		}
+
+		found = true
+
+		tc, err := o.text.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "env %s", key)
+		}
+
+		if err := tc.UnmarshalText(fv, []byte(value)); err != nil {
+			return pkgerrors.Wrapf(err, "env %s", key)
+		}
+		return nil
+	})
+	return
+}
+
+func (o *envOptions) marshalStruct(buf *bytes.Buffer, rv reflect.Value, prefix string) error {
+	return eachStructField(rv, TagEnv, false, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		key := envKey(prefix, name, envNameTagged(field))
+
+		if o.text.isNestedStruct(fv.Type()) {
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				fv = reflectx.New(fv.Type())
+			}
+			return o.marshalStruct(buf, reflectx.Indirect(fv), key)
+		}
+
+		tc, err := o.text.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "env %s", key)
+		}
+
+		if tc.skipNull(fv) {
+			return nil
+		}
+
+		text, err := tc.MarshalText(fv)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "env %s", key)
+		}
+
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(quoteEnvValue(string(text)))
+		buf.WriteByte('\n')
+		return nil
+	})
+}
+
+// quoteEnvValue quotes value when it could not be kept as-is in .env file
+func quoteEnvValue(value string) string {
+	if strings.ContainsAny(value, " \t\r\n\"'`#$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
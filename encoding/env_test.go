@@ -0,0 +1,149 @@
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type EnvDB struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT"`
+}
+
+type EnvLog struct {
+	Level string
+}
+
+type EnvConfig struct {
+	EnvLog
+	Name     string        `env:"NAME"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Hosts    []string      `env:"HOSTS"`
+	Debug    *bool         `env:"DEBUG"`
+	Secret   []byte        `env:"SECRET" text:"bytes=hex"`
+	DB       EnvDB         `env:"DB"`
+	Replica  *EnvDB        `env:"REPLICA"`
+	Cache    *EnvDB        `env:"CACHE"`
+	Ignored  string        `env:"-"`
+	internal string
+}
+
+func TestUnmarshalEnv(t *testing.T) {
+	c := EnvConfig{}
+
+	err := UnmarshalEnv(&c, WithEnvPrefix("APP"), WithEnvMap(map[string]string{
+		"APP_LEVEL":        "debug",
+		"APP_NAME":         "srv",
+		"APP_TIMEOUT":      "5s",
+		"APP_HOSTS":        "a,b",
+		"APP_DEBUG":        "true",
+		"APP_SECRET":       "beef",
+		"APP_DB_HOST":      "db",
+		"APP_DB_PORT":      "5432",
+		"APP_REPLICA_HOST": "replica",
+		"APP_CACHE_HOST":   "cache",
+		"APP_IGNORED":      "x",
+	}))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(EnvConfig{
+		EnvLog:  EnvLog{Level: "debug"},
+		Name:    "srv",
+		Timeout: 5 * time.Second,
+		Hosts:   []string{"a", "b"},
+		Debug:   ptr.Bool(true),
+		Secret:  []byte{0xbe, 0xef},
+		DB:      EnvDB{Host: "db", Port: 5432},
+		Replica: &EnvDB{Host: "replica"},
+		Cache:   &EnvDB{Host: "cache"},
+	}))
+
+	t.Run("required", func(t *testing.T) {
+		err := UnmarshalEnv(&EnvConfig{}, WithEnvMap(map[string]string{}))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("DB_HOST"))
+
+		err = UnmarshalEnv(&EnvConfig{}, WithEnvMap(map[string]string{
+			"DB_HOST":      "db",
+			"REPLICA_PORT": "1",
+		}))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(Equal("missing required env REPLICA_HOST, CACHE_HOST"))
+	})
+
+	t.Run("tagged name as it is", func(t *testing.T) {
+		v := struct {
+			Port int `env:"Port"`
+			Host string
+		}{}
+		NewWithT(t).Expect(UnmarshalEnv(&v, WithEnvMap(map[string]string{"Port": "80", "HOST": "h"}))).To(BeNil())
+		NewWithT(t).Expect(v.Port).To(Equal(80))
+		NewWithT(t).Expect(v.Host).To(Equal("h"))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		err := UnmarshalEnv(&EnvConfig{}, WithEnvMap(map[string]string{
+			"DB_HOST": "db",
+			"DB_PORT": "x",
+		}))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("DB_PORT"))
+	})
+
+	t.Run("os env", func(t *testing.T) {
+		t.Setenv("X_TEST_DB_HOST", "localhost")
+		t.Setenv("X_TEST_DB_PORT", "80")
+
+		db := EnvDB{}
+		NewWithT(t).Expect(UnmarshalEnv(&db, WithEnvPrefix("X_TEST_DB"))).To(BeNil())
+		NewWithT(t).Expect(db).To(Equal(EnvDB{Host: "localhost", Port: 80}))
+	})
+
+	t.Run("not ptr", func(t *testing.T) {
+		NewWithT(t).Expect(UnmarshalEnv(EnvDB{})).NotTo(BeNil())
+		NewWithT(t).Expect(UnmarshalEnv(ptr.Int(1))).NotTo(BeNil())
+	})
+}
+
+func TestMarshalEnv(t *testing.T) {
+	data, err := MarshalEnv(EnvConfig{
+		Name:    "my srv",
+		Timeout: time.Second,
+		Hosts:   []string{"a", "b"},
+		DB:      EnvDB{Host: "db", Port: 5432},
+	}, WithEnvPrefix("APP"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`APP_LEVEL=
+APP_NAME="my srv"
+APP_TIMEOUT=1s
+APP_HOSTS=a,b
+APP_DEBUG=
+APP_SECRET=
+APP_DB_HOST=db
+APP_DB_PORT=5432
+APP_REPLICA_HOST=
+APP_REPLICA_PORT=0
+APP_CACHE_HOST=
+APP_CACHE_PORT=0
+`))
+
+	t.Run("template from nil", func(t *testing.T) {
+		data, err := MarshalEnv((*EnvDB)(nil))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal("HOST=\nPORT=0\n"))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		c := EnvConfig{
+			Name:   "a \"b\"",
+			Secret: []byte{1},
+			DB:     EnvDB{Host: "db"},
+		}
+		data, err := MarshalEnv(c)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(ContainSubstring(`NAME="a \"b\""`))
+		NewWithT(t).Expect(string(data)).To(ContainSubstring(`SECRET=01`))
+	})
+}
@@ -0,0 +1,55 @@
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnmarshalTextError describes text which could not be decoded into a value of Type.
+type UnmarshalTextError struct {
+	Type reflect.Type
+	Text string
+	Err  error
+}
+
+func (e *UnmarshalTextError) Error() string {
+	return fmt.Sprintf("unmarshal text %q to %s failed: %s", e.Text, e.Type, e.Err)
+}
+
+func (e *UnmarshalTextError) Unwrap() error {
+	return e.Err
+}
+
+func newUnmarshalTextError(typ reflect.Type, data []byte, err error) error {
+	return &UnmarshalTextError{Type: typ, Text: string(data), Err: err}
+}
+
+// SyntaxError describes malformed line of text files like .env, .properties and INI.
+type SyntaxError struct {
+	Line int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// CSVError describes failure of converting cell of CSV row.
+type CSVError struct {
+	Line   int
+	Column int
+	Name   string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("line %d, column %d (%s): %s", e.Line, e.Column, e.Name, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,158 @@
+package encoding
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagUsage is the struct tag for usage of flag
+const TagUsage = "usage"
+
+// BindFlags registers one flag for each field of struct v into fs, named by tag or lower-cased field name.
+// Defaults are the current field values, and usage is from the `usage` tag.
+// Slice fields accumulate repeated flags, nested structs become dotted flag names like `db.host`.
+// Nil ptr structs are allocated only when any flag of them set.
+// Flag names already defined in fs, or defined by more than one field, return error.
+func BindFlags(fs *flag.FlagSet, v any, tag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("bind flags need non-nil ptr value, but got %T", v)
+	}
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("bind flags need struct value, but got %T", v)
+	}
+
+	return bindFlags(fs, rv, rv.Type(), tag, "", nil, map[string][]int{})
+}
+
+// bindFlags registers flags for fields of struct typ, which is at index of root.
+// bound is index of the field of each flag registered, to report which fields define the same flag.
+func bindFlags(fs *flag.FlagSet, root reflect.Value, typ reflect.Type, tag string, prefix string, index []int, bound map[string][]int) (err error) {
+	walkStructFields(typ, tag, index, func(field types.StructField, name string, omitempty bool, fieldIndex []int) bool {
+		fieldType := field.(*types.RStructField).StructField.Type
+
+		if name == field.Name() {
+			name = strings.ToLower(name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if DefaultTextCodec.isNestedStruct(fieldType) {
+			err = bindFlags(fs, root, reflectx.Deref(fieldType), tag, name, fieldIndex, bound)
+			return err == nil
+		}
+
+		tc, e := DefaultTextCodec.ForField(field.Tag())
+		if e != nil {
+			err = pkgerrors.Wrapf(e, "flag %s", name)
+			return false
+		}
+
+		// FlagSet.Var panics on redefined flag
+		if fs.Lookup(name) != nil {
+			if other, ok := bound[name]; ok {
+				err = pkgerrors.Errorf("flag %s of field %s is redefined by field %s", name, fieldPath(root.Type(), other), fieldPath(root.Type(), fieldIndex))
+			} else {
+				err = pkgerrors.Errorf("flag %s of field %s is already defined", name, fieldPath(root.Type(), fieldIndex))
+			}
+			return false
+		}
+		bound[name] = fieldIndex
+
+		fs.Var(&flagValue{
+			tc:    tc,
+			root:  root,
+			index: fieldIndex,
+			typ:   fieldType,
+			list:  fieldType.Kind() == reflect.Slice && isTextList(fieldType),
+		}, name, field.Tag().Get(TagUsage))
+
+		return true
+	})
+	return
+}
+
+// fieldPath returns dotted names of the field of struct typ by index path, like `DB.Host`
+func fieldPath(typ reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, idx := range index {
+		sf := reflectx.Deref(typ).Field(idx)
+		names[i] = sf.Name
+		typ = sf.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// flagValue implements flag.Value by TextCodec
+type flagValue struct {
+	tc *TextCodec
+	// root struct, and index path of the field from it.
+	// nil ptr structs on the path are allocated when set.
+	root  reflect.Value
+	index []int
+	typ   reflect.Type
+	list  bool
+	// set marks the list reset from default values
+	set bool
+}
+
+// value returns the field, false when nil ptr struct on the path not allocated
+func (f *flagValue) value(alloc bool) (reflect.Value, bool) {
+	return fieldByIndex(f.root, f.index, alloc)
+}
+
+func (f *flagValue) String() string {
+	// flag.isZeroValue creates zero flagValue,
+	// empty values render as "" too, to omit default in usage.
+	if f.tc == nil || !f.root.IsValid() {
+		return ""
+	}
+	rv, ok := f.value(false)
+	if !ok || reflectx.IsEmptyValue(rv) {
+		return ""
+	}
+	text, err := f.tc.MarshalText(rv)
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+func (f *flagValue) Set(s string) error {
+	rv, _ := f.value(true)
+
+	if !f.list {
+		return f.tc.UnmarshalText(rv, []byte(s))
+	}
+
+	if !f.set {
+		rv.Set(reflect.MakeSlice(f.typ, 0, 1))
+		f.set = true
+	}
+
+	elem := reflect.New(f.typ.Elem()).Elem()
+	if err := f.tc.UnmarshalText(elem, []byte(s)); err != nil {
+		return err
+	}
+	rv.Set(reflect.Append(rv, elem))
+	return nil
+}
+
+func (f *flagValue) Get() any {
+	if rv, ok := f.value(false); ok {
+		return rv.Interface()
+	}
+	return reflect.Zero(f.typ).Interface()
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.typ != nil && reflectx.Deref(f.typ).Kind() == reflect.Bool
+}
@@ -0,0 +1,142 @@
+package encoding
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type FlagDB struct {
+	Host string `flag:"host" usage:"database host"`
+	Port int    `flag:"port"`
+}
+
+type FlagOptions struct {
+	Verbose  bool          `flag:"v" usage:"verbose output"`
+	Name     string        `usage:"name of service"`
+	Timeout  time.Duration `flag:"timeout"`
+	Tags     []string      `flag:"tag" usage:"repeatable tag"`
+	Ports    []int         `flag:"port"`
+	Debug    *bool         `flag:"debug"`
+	Secret   []byte        `flag:"secret" text:"bytes=hex"`
+	DB       FlagDB        `flag:"db"`
+	Cache    *FlagDB
+	Replica  *FlagDB
+	Ignored  string `flag:"-"`
+	internal string
+}
+
+type FlagEmbedA struct {
+	Host string
+}
+
+type FlagEmbedB struct {
+	Host string
+}
+
+func TestBindFlags(t *testing.T) {
+	opts := FlagOptions{
+		Name:    "srv",
+		Timeout: time.Second,
+		Tags:    []string{"default"},
+		DB:      FlagDB{Host: "localhost", Port: 5432},
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	NewWithT(t).Expect(BindFlags(fs, &opts, "flag")).To(BeNil())
+
+	NewWithT(t).Expect(fs.Lookup("name").DefValue).To(Equal("srv"))
+	NewWithT(t).Expect(fs.Lookup("name").Usage).To(Equal("name of service"))
+	NewWithT(t).Expect(fs.Lookup("timeout").DefValue).To(Equal("1s"))
+	NewWithT(t).Expect(fs.Lookup("db.host").DefValue).To(Equal("localhost"))
+	NewWithT(t).Expect(fs.Lookup("cache.host")).NotTo(BeNil())
+	NewWithT(t).Expect(fs.Lookup("Ignored")).To(BeNil())
+	NewWithT(t).Expect(fs.Lookup("internal")).To(BeNil())
+
+	err := fs.Parse([]string{
+		"-v",
+		"-timeout", "5s",
+		"-tag", "a",
+		"-tag", "b,c",
+		"-port", "80", "-port=443",
+		"-debug",
+		"-secret", "beef",
+		"-db.port", "3306",
+		"-cache.host", "redis",
+		"arg",
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(fs.Args()).To(Equal([]string{"arg"}))
+
+	NewWithT(t).Expect(opts).To(Equal(FlagOptions{
+		Verbose: true,
+		Name:    "srv",
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a", "b,c"},
+		Ports:   []int{80, 443},
+		Debug:   ptr.Bool(true),
+		Secret:  []byte{0xbe, 0xef},
+		DB:      FlagDB{Host: "localhost", Port: 3306},
+		Cache:   &FlagDB{Host: "redis"},
+	}))
+
+	NewWithT(t).Expect(fs.Lookup("port").Value.(flag.Getter).Get()).To(Equal([]int{80, 443}))
+	NewWithT(t).Expect(fs.Lookup("replica.port").Value.(flag.Getter).Get()).To(Equal(0))
+
+	t.Run("redefined", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		err := BindFlags(fs, &struct {
+			A string `flag:"x"`
+			B int    `flag:"x"`
+		}{}, "flag")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(Equal("flag x of field A is redefined by field B"))
+
+		fs = flag.NewFlagSet("test", flag.ContinueOnError)
+		err = BindFlags(fs, &struct {
+			FlagEmbedA
+			*FlagEmbedB
+		}{}, "flag")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(Equal("flag host of field FlagEmbedA.Host is redefined by field FlagEmbedB.Host"))
+
+		fs = flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("name", "", "")
+		err = BindFlags(fs, &FlagOptions{}, "flag")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(Equal("flag name of field Name is already defined"))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+
+		NewWithT(t).Expect(BindFlags(fs, &FlagOptions{}, "flag")).To(BeNil())
+		NewWithT(t).Expect(fs.Parse([]string{"-db.port", "x"})).NotTo(BeNil())
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		buf := bytes.NewBuffer(nil)
+		fs.SetOutput(buf)
+
+		NewWithT(t).Expect(BindFlags(fs, &FlagOptions{Name: "srv"}, "flag")).To(BeNil())
+		fs.PrintDefaults()
+
+		NewWithT(t).Expect(buf.String()).To(ContainSubstring("database host"))
+		NewWithT(t).Expect(buf.String()).To(ContainSubstring("(default srv)"))
+		NewWithT(t).Expect(buf.String()).NotTo(ContainSubstring("(default 0)"))
+	})
+
+	t.Run("not ptr", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		NewWithT(t).Expect(BindFlags(fs, FlagOptions{}, "flag")).NotTo(BeNil())
+	})
+}
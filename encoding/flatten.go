@@ -0,0 +1,269 @@
+package encoding
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// Flatten converts struct v to dotted key/value map, like properties-style stores.
+// Fields are named by tag, nested structs become `a.b.c`, slices `a.0` and maps `a.key`.
+// Embedded structs are inlined unless kept nested by tag, like types.EachField.
+// Leaf values are converted by MarshalText, and nil pointers are skipped.
+func Flatten(v any, tag string) (map[string]string, error) {
+	m := map[string]string{}
+
+	rv, ok := indirectStruct(v, false)
+	if !ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && reflectx.Deref(rv.Type()).Kind() == reflect.Struct {
+			return m, nil
+		}
+		return nil, pkgerrors.Errorf("flatten need struct value, but got %T", v)
+	}
+
+	if err := flattenStruct(m, rv, tag, ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Unflatten fills struct v from dotted key/value map m, the reverse of Flatten.
+// nil pointers are only allocated when any key under them found.
+// indexes of list elements should be dense from 0, like `a.0` and `a.1`, otherwise error returns.
+func Unflatten(m map[string]string, v any, tag string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("unflatten need non-nil ptr value, but got %T", v)
+	}
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("unflatten need struct value, but got %T", v)
+	}
+
+	_, err := unflattenStruct(m, rv, tag, "")
+	return err
+}
+
+func flattenKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isTextMap reports whether values of typ should be handled entry by entry,
+// as maps which are not text (un)marshaler themselves.
+func isTextMap(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Map {
+		return false
+	}
+	return !typ.Implements(textMarshalerType) && !reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+func flattenStruct(m map[string]string, rv reflect.Value, tag string, prefix string) error {
+	return eachStructField(rv, tag, false, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		tc, err := DefaultTextCodec.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "flatten %s", flattenKey(prefix, name))
+		}
+		return flattenValue(m, tc, fv, tag, flattenKey(prefix, name))
+	})
+}
+
+func flattenValue(m map[string]string, tc *TextCodec, rv reflect.Value, tag string, key string) error {
+	if isNil(rv) {
+		return nil
+	}
+
+	if tc.isNestedStruct(rv.Type()) {
+		return flattenStruct(m, reflectx.Indirect(rv), tag, key)
+	}
+
+	rv = reflectx.Indirect(rv)
+
+	switch {
+	case isTextList(rv.Type()):
+		for i := 0; i < rv.Len(); i++ {
+			if err := flattenValue(m, tc, rv.Index(i), tag, flattenKey(key, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isTextMap(rv.Type()):
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := tc.MarshalText(iter.Key())
+			if err != nil {
+				return pkgerrors.Wrapf(err, "flatten %s", key)
+			}
+			if err := flattenValue(m, tc, iter.Value(), tag, flattenKey(key, string(k))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	text, err := tc.MarshalText(rv)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "flatten %s", key)
+	}
+	m[key] = string(text)
+	return nil
+}
+
+// unflattenStruct returns whether any key under prefix found
+func unflattenStruct(m map[string]string, rv reflect.Value, tag string, prefix string) (found bool, err error) {
+	err = eachStructField(rv, tag, true, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		tc, err := DefaultTextCodec.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "unflatten %s", flattenKey(prefix, name))
+		}
+		ok, err := unflattenValue(m, tc, fv, tag, flattenKey(prefix, name))
+		if err != nil {
+			return err
+		}
+		found = found || ok
+		return nil
+	})
+	return
+}
+
+func unflattenValue(m map[string]string, tc *TextCodec, rv reflect.Value, tag string, key string) (bool, error) {
+	typ := reflectx.Deref(rv.Type())
+
+	switch {
+	case tc.isNestedStruct(typ):
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			nv := reflectx.New(rv.Type())
+			found, err := unflattenStruct(m, reflectx.Indirect(nv), tag, key)
+			if found {
+				rv.Set(nv)
+			}
+			return found, err
+		}
+		return unflattenStruct(m, reflectx.Indirect(rv), tag, key)
+	case isTextList(typ), isTextMap(typ):
+		// elements of list and composite values of map are keyed by the segment before next '.',
+		// otherwise the whole rest of key is the map key, which may contain '.'
+		whole := typ.Kind() == reflect.Map && !isFlattenComposite(tc, typ.Elem())
+
+		segments := flattenSegments(m, key, whole)
+		if len(segments) == 0 {
+			return false, nil
+		}
+
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+
+		if typ.Kind() == reflect.Map {
+			return true, unflattenMap(m, tc, rv, tag, key, segments)
+		}
+		return true, unflattenList(m, tc, rv, tag, key, segments)
+	}
+
+	text, ok := m[key]
+	if !ok {
+		return false, nil
+	}
+	if err := tc.UnmarshalText(rv, []byte(text)); err != nil {
+		return true, pkgerrors.Wrapf(err, "unflatten %s", key)
+	}
+	return true, nil
+}
+
+// unflattenList decodes elements of list keyed by indexes, which must be dense from 0,
+// so length of list is bounded by the number of keys instead of any index.
+func unflattenList(m map[string]string, tc *TextCodec, rv reflect.Value, tag string, key string, segments []string) error {
+	n := len(segments)
+	indexes := make([]int, n)
+	seen := make([]bool, n)
+	for i, s := range segments {
+		idx, err := strconv.Atoi(s)
+		if err != nil || idx < 0 {
+			return pkgerrors.Errorf("unflatten %s: invalid index %q", key, s)
+		}
+		if idx >= n || seen[idx] {
+			return pkgerrors.Errorf("unflatten %s: indexes should be dense from 0, but got %d of %d keys", key, idx, n)
+		}
+		seen[idx] = true
+		indexes[i] = idx
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+	case reflect.Array:
+		if n > rv.Len() {
+			return pkgerrors.Errorf("unflatten %s: got index %d, but array length is %d", key, n-1, rv.Len())
+		}
+		rv.Set(reflect.Zero(rv.Type()))
+	}
+
+	for i, idx := range indexes {
+		if _, err := unflattenValue(m, tc, rv.Index(idx), tag, flattenKey(key, segments[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unflattenMap(m map[string]string, tc *TextCodec, rv reflect.Value, tag string, key string, segments []string) error {
+	mv := reflect.MakeMapWithSize(rv.Type(), len(segments))
+
+	for _, s := range segments {
+		k := reflect.New(rv.Type().Key()).Elem()
+		if err := tc.UnmarshalText(k, []byte(s)); err != nil {
+			return pkgerrors.Wrapf(err, "unflatten %s", flattenKey(key, s))
+		}
+
+		v := reflect.New(rv.Type().Elem()).Elem()
+		if _, err := unflattenValue(m, tc, v, tag, flattenKey(key, s)); err != nil {
+			return err
+		}
+
+		mv.SetMapIndex(k, v)
+	}
+
+	rv.Set(mv)
+	return nil
+}
+
+// isFlattenComposite reports whether values of typ are flattened to multiple keys
+func isFlattenComposite(tc *TextCodec, typ reflect.Type) bool {
+	typ = reflectx.Deref(typ)
+	return tc.isNestedStruct(typ) || isTextList(typ) || isTextMap(typ)
+}
+
+// flattenSegments returns sorted unique segments of keys under prefix
+func flattenSegments(m map[string]string, prefix string, whole bool) []string {
+	prefix += "."
+
+	set := map[string]bool{}
+	for k := range m {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		s := k[len(prefix):]
+		if !whole {
+			s, _, _ = strings.Cut(s, ".")
+		}
+		set[s] = true
+	}
+
+	segments := make([]string, 0, len(set))
+	for s := range set {
+		segments = append(segments, s)
+	}
+	sort.Strings(segments)
+	return segments
+}
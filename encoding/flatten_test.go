@@ -0,0 +1,129 @@
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type FlattenEndpoint struct {
+	Host string `kv:"host"`
+	Port int    `kv:"port"`
+}
+
+type FlattenMeta struct {
+	Owner string `kv:"owner"`
+}
+
+type FlattenKept struct {
+	Region string `kv:"region"`
+}
+
+type FlattenConfig struct {
+	FlattenMeta
+	FlattenKept `kv:"kept"`
+	Name        string                     `kv:"name"`
+	Timeout     time.Duration              `kv:"timeout"`
+	Tags        []string                   `kv:"tags"`
+	Ports       [2]int                     `kv:"ports"`
+	Labels      map[string]string          `kv:"labels"`
+	Endpoints   []FlattenEndpoint          `kv:"endpoints"`
+	Named       map[string]FlattenEndpoint `kv:"named"`
+	Primary     FlattenEndpoint            `kv:"primary"`
+	Replica     *FlattenEndpoint           `kv:"replica"`
+	Backup      *FlattenEndpoint           `kv:"backup"`
+	Digest      []byte                     `kv:"digest" text:"bytes=hex"`
+	Ignored     string                     `kv:"-"`
+}
+
+func TestFlatten(t *testing.T) {
+	c := FlattenConfig{
+		FlattenMeta: FlattenMeta{Owner: "ops"},
+		FlattenKept: FlattenKept{Region: "eu"},
+		Name:        "srv",
+		Timeout:     time.Second,
+		Tags:        []string{"a", "b"},
+		Ports:       [2]int{80, 443},
+		Labels:      map[string]string{"app.kubernetes.io/name": "srv"},
+		Endpoints:   []FlattenEndpoint{{Host: "a", Port: 1}},
+		Named:       map[string]FlattenEndpoint{"x": {Host: "x", Port: 2}},
+		Primary:     FlattenEndpoint{Host: "p", Port: 3},
+		Replica:     &FlattenEndpoint{Host: "r"},
+		Digest:      []byte{0xff},
+		Ignored:     "ignored",
+	}
+
+	m, err := Flatten(&c, "kv")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(m).To(Equal(map[string]string{
+		"owner":                         "ops",
+		"kept.region":                   "eu",
+		"name":                          "srv",
+		"timeout":                       "1s",
+		"tags.0":                        "a",
+		"tags.1":                        "b",
+		"ports.0":                       "80",
+		"ports.1":                       "443",
+		"labels.app.kubernetes.io/name": "srv",
+		"endpoints.0.host":              "a",
+		"endpoints.0.port":              "1",
+		"named.x.host":                  "x",
+		"named.x.port":                  "2",
+		"primary.host":                  "p",
+		"primary.port":                  "3",
+		"replica.host":                  "r",
+		"replica.port":                  "0",
+		"digest":                        "ff",
+	}))
+
+	t.Run("unflatten", func(t *testing.T) {
+		v := FlattenConfig{}
+		NewWithT(t).Expect(Unflatten(m, &v, "kv")).To(BeNil())
+
+		c.Ignored = ""
+		NewWithT(t).Expect(v).To(Equal(c))
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		v := FlattenConfig{}
+		NewWithT(t).Expect(Unflatten(map[string]string{
+			"tags.1":      "b",
+			"tags.0":      "a",
+			"replica.x":   "unknown key",
+			"backup.port": "1",
+		}, &v, "kv")).To(BeNil())
+		NewWithT(t).Expect(v.Tags).To(Equal([]string{"a", "b"}))
+		NewWithT(t).Expect(v.Replica).To(BeNil())
+		NewWithT(t).Expect(v.Backup).To(Equal(&FlattenEndpoint{Port: 1}))
+	})
+
+	t.Run("sparse list", func(t *testing.T) {
+		v := FlattenConfig{}
+		NewWithT(t).Expect(Unflatten(map[string]string{"tags.2": "c"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(Unflatten(map[string]string{"tags.1000000000": "c"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(Unflatten(map[string]string{"tags.1": "b", "tags.01": "b"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(v.Tags).To(BeNil())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		v := FlattenConfig{}
+		NewWithT(t).Expect(Unflatten(map[string]string{"tags.x": "a"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(Unflatten(map[string]string{"ports.2": "1"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(Unflatten(map[string]string{"primary.port": "x"}, &v, "kv")).NotTo(BeNil())
+		NewWithT(t).Expect(Unflatten(map[string]string{}, v, "kv")).NotTo(BeNil())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		m, err := Flatten((*FlattenConfig)(nil), "kv")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(m).To(BeEmpty())
+
+		m, err = Flatten(struct {
+			P *int `kv:"p"`
+		}{P: ptr.Int(1)}, "kv")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(m).To(Equal(map[string]string{"p": "1"}))
+	})
+}
@@ -0,0 +1,138 @@
+package encoding
+
+import (
+	"net/url"
+	"reflect"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// FormCodec converts structs to and from url.Values.
+type FormCodec struct {
+	// Tag for field names, like `form` or `json`
+	Tag string
+	// Text converts field values, DefaultTextCodec when nil
+	Text *TextCodec
+}
+
+var DefaultFormCodec = FormCodec{Tag: "form"}
+
+func MarshalForm(v any) (url.Values, error) {
+	return DefaultFormCodec.Marshal(v)
+}
+
+func UnmarshalForm(values url.Values, v any) error {
+	return DefaultFormCodec.Unmarshal(values, v)
+}
+
+func (c FormCodec) textCodec(field types.StructField) (*TextCodec, error) {
+	tc := c.Text
+	if tc == nil {
+		tc = DefaultTextCodec
+	}
+	return tc.ForField(field.Tag())
+}
+
+func (c FormCodec) Marshal(v any) (url.Values, error) {
+	rv, ok := indirectStruct(v, false)
+	if !ok {
+		return nil, pkgerrors.Errorf("marshal form need struct value, but got %T", v)
+	}
+
+	values := url.Values{}
+
+	err := eachStructField(rv, c.Tag, false, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		if omitempty && reflectx.IsEmptyValue(fv) {
+			return nil
+		}
+
+		tc, err := c.textCodec(field)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "marshal form field %s failed", name)
+		}
+
+		// nil ptr is absent, unless it could be decoded back from the null text
+		if fv.Kind() == reflect.Ptr && fv.IsNil() && tc.NullPolicy() != NullAsText {
+			return nil
+		}
+
+		if isTextList(fv.Type()) {
+			if fv.Len() == 0 {
+				return nil
+			}
+			list := make([]string, fv.Len())
+			for i := range list {
+				text, err := tc.MarshalText(fv.Index(i))
+				if err != nil {
+					return pkgerrors.Wrapf(err, "marshal form field %s failed", name)
+				}
+				list[i] = string(text)
+			}
+			values[name] = list
+			return nil
+		}
+
+		text, err := tc.MarshalText(fv)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "marshal form field %s failed", name)
+		}
+		values.Set(name, string(text))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (c FormCodec) Unmarshal(values url.Values, v any) error {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return pkgerrors.Errorf("unmarshal form need non-nil ptr value, but got %T", v)
+		}
+	}
+
+	rv, ok = indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("unmarshal form need struct value, but got %T", v)
+	}
+
+	return eachStructField(rv, c.Tag, true, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		list, ok := values[name]
+		if !ok || len(list) == 0 {
+			return nil
+		}
+
+		tc, err := c.textCodec(field)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "unmarshal form field %s failed", name)
+		}
+
+		if isTextList(fv.Type()) {
+			switch fv.Kind() {
+			case reflect.Slice:
+				fv.Set(reflect.MakeSlice(fv.Type(), len(list), len(list)))
+			case reflect.Array:
+				if len(list) > fv.Len() {
+					return pkgerrors.Errorf("unmarshal form field %s failed: got %d values, but array length is %d", name, len(list), fv.Len())
+				}
+			}
+			for i := 0; i < len(list); i++ {
+				if err := tc.UnmarshalText(fv.Index(i), []byte(list[i])); err != nil {
+					return pkgerrors.Wrapf(err, "unmarshal form field %s failed", name)
+				}
+			}
+			return nil
+		}
+
+		if err := tc.UnmarshalText(fv, []byte(list[0])); err != nil {
+			return pkgerrors.Wrapf(err, "unmarshal form field %s failed", name)
+		}
+		return nil
+	})
+}
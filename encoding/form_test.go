@@ -0,0 +1,157 @@
+package encoding
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type FormPart struct {
+	Page int `form:"page,omitempty"`
+}
+
+type FormQuery struct {
+	*FormPart
+	Name     string      `form:"name"`
+	Nickname *string     `form:"nickname"`
+	Tags     []string    `form:"tag"`
+	IDs      [2]NamedInt `form:"id"`
+	Timeout  Duration    `form:"timeout,omitempty"`
+	Bytes    []byte      `form:"bytes,omitempty"`
+	Ignored  string      `form:"-"`
+	Desc     string      `json:"desc"`
+	internal string
+}
+
+type FormInner struct {
+	Name string `form:"name2"`
+}
+
+type FormOuter struct {
+	FormInner
+	Name string `form:"name"`
+}
+
+type FormA struct {
+	ID int `form:"a"`
+}
+
+type FormB struct {
+	ID int `form:"b"`
+}
+
+type FormAmbiguous struct {
+	FormA
+	FormB
+}
+
+func TestMarshalForm(t *testing.T) {
+	values, err := MarshalForm(&FormQuery{
+		FormPart: &FormPart{Page: 2},
+		Name:     "x",
+		Tags:     []string{"a", "b"},
+		IDs:      [2]NamedInt{1, 2},
+		Timeout:  Duration(time.Second),
+		Ignored:  "ignored",
+		Desc:     "desc",
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(values).To(Equal(url.Values{
+		"page":    {"2"},
+		"name":    {"x"},
+		"tag":     {"a", "b"},
+		"id":      {"1", "2"},
+		"timeout": {"1s"},
+		"Desc":    {"desc"},
+	}))
+
+	t.Run("omitempty and nil", func(t *testing.T) {
+		values, err := MarshalForm(FormQuery{})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(values).To(Equal(url.Values{
+			"name": {""},
+			"id":   {"0", "0"},
+			"Desc": {""},
+		}))
+	})
+
+	t.Run("custom tag", func(t *testing.T) {
+		values, err := FormCodec{Tag: "json"}.Marshal(FormQuery{Desc: "desc"})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(values.Get("desc")).To(Equal("desc"))
+		NewWithT(t).Expect(values.Has("Name")).To(BeTrue())
+	})
+
+	t.Run("shadowed and ambiguous embedded fields", func(t *testing.T) {
+		values, err := MarshalForm(FormOuter{FormInner: FormInner{Name: "inner"}, Name: "outer"})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(values).To(Equal(url.Values{"name": {"outer"}, "name2": {"inner"}}))
+
+		values, err = MarshalForm(FormAmbiguous{FormA: FormA{ID: 1}, FormB: FormB{ID: 2}})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(values).To(Equal(url.Values{"a": {"1"}, "b": {"2"}}))
+	})
+
+	t.Run("not struct", func(t *testing.T) {
+		_, err := MarshalForm(1)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+func TestUnmarshalForm(t *testing.T) {
+	q := FormQuery{}
+
+	err := UnmarshalForm(url.Values{
+		"page":     {"3"},
+		"name":     {"x", "y"},
+		"nickname": {"n"},
+		"tag":      {"a", "b", "c"},
+		"id":       {"1", "2"},
+		"timeout":  {"2s"},
+		"bytes":    {"MTEx"},
+		"Ignored":  {"ignored"},
+	}, &q)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(q).To(Equal(FormQuery{
+		FormPart: &FormPart{Page: 3},
+		Name:     "x",
+		Nickname: ptr.String("n"),
+		Tags:     []string{"a", "b", "c"},
+		IDs:      [2]NamedInt{1, 2},
+		Timeout:  Duration(2 * time.Second),
+		Bytes:    []byte("111"),
+	}))
+
+	t.Run("invalid value", func(t *testing.T) {
+		err := UnmarshalForm(url.Values{"id": {"x"}}, &FormQuery{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("too many values of array", func(t *testing.T) {
+		err := UnmarshalForm(url.Values{"id": {"1", "2", "3"}}, &FormQuery{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("invalid value of embedded field", func(t *testing.T) {
+		err := UnmarshalForm(url.Values{"page": {"x"}, "name": {"x"}}, &FormQuery{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("shadowed and ambiguous embedded fields", func(t *testing.T) {
+		o := FormOuter{}
+		NewWithT(t).Expect(UnmarshalForm(url.Values{"name": {"outer"}, "name2": {"inner"}}, &o)).To(BeNil())
+		NewWithT(t).Expect(o).To(Equal(FormOuter{FormInner: FormInner{Name: "inner"}, Name: "outer"}))
+
+		a := FormAmbiguous{}
+		NewWithT(t).Expect(UnmarshalForm(url.Values{"a": {"1"}, "b": {"2"}}, &a)).To(BeNil())
+		NewWithT(t).Expect(a).To(Equal(FormAmbiguous{FormA: FormA{ID: 1}, FormB: FormB{ID: 2}}))
+	})
+
+	t.Run("not ptr", func(t *testing.T) {
+		err := UnmarshalForm(url.Values{}, FormQuery{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,49 @@
+package encoding
+
+import (
+	"reflect"
+)
+
+// AppendTextEntryKey appends key of struct field entry to dst for methods generated by cmd/textgen,
+// with separator before unless it is the first entry appended since start.
+func AppendTextEntryKey(dst []byte, start int, key string) []byte {
+	c := DefaultTextCodec
+	if len(dst) > start {
+		dst = append(dst, c.separator)
+	}
+	i := len(dst)
+	dst = c.escapeFrom(append(dst, key...), i)
+	return append(dst, c.keyValueSeparator)
+}
+
+// EscapeText escapes separators in dst[start:] for methods generated by cmd/textgen
+func EscapeText(dst []byte, start int) []byte {
+	return DefaultTextCodec.escapeFrom(dst, start)
+}
+
+// EachTextEntry calls each with unescaped key and value of k=v entries in data for methods generated by cmd/textgen
+func EachTextEntry(data []byte, each func(key string, value []byte) error) error {
+	return DefaultTextCodec.eachEntry(data, func(key []byte, value []byte) error {
+		return each(string(key), value)
+	})
+}
+
+// AppendFieldText appends text of v to dst with options of the `text` tag of field,
+// for methods generated by cmd/textgen
+func AppendFieldText(dst []byte, tag reflect.StructTag, v any) ([]byte, error) {
+	c, err := DefaultTextCodec.ForField(tag)
+	if err != nil {
+		return nil, err
+	}
+	return c.AppendText(dst, v)
+}
+
+// UnmarshalFieldText decodes data into v with options of the `text` tag of field,
+// for methods generated by cmd/textgen
+func UnmarshalFieldText(tag reflect.StructTag, v any, data []byte) error {
+	c, err := DefaultTextCodec.ForField(tag)
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalText(v, data)
+}
@@ -0,0 +1,422 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagINI is the struct tag for INI keys and sections.
+//
+//	DB Database `ini:"database"`
+const TagINI = "ini"
+
+// UnmarshalINI fills struct v from INI file.
+//
+//	; default section
+//	name = srv
+//
+//	[database]
+//	host = localhost
+//	replica = r1
+//	replica = r2
+//
+//	[database.pool]
+//	size = 10
+//
+// Keys before any section belong to the default section, mapped to fields of v.
+// Each [section] is mapped to nested struct field named by the `ini` tag, and [a.b] to nested field b of section a.
+// Map fields of scalar values are sections of arbitrary keys.
+// Repeated keys are appended into lists, otherwise the last one wins.
+// Unknown sections and keys are ignored, and errors are *SyntaxError with line number.
+func UnmarshalINI(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("unmarshal ini need non-nil ptr value, but got %T", v)
+	}
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("unmarshal ini need struct value, but got %T", v)
+	}
+
+	d := &iniDecoder{
+		l:       newLineReader(r),
+		section: rv,
+		root:    rv,
+		counts:  map[string]int{},
+	}
+	return d.decode()
+}
+
+// MarshalINI writes struct v as INI file.
+// Fields of v are written into the default section first, then nested structs and maps as sections in order of fields.
+// Lists are written as repeated keys, and nil pointers are skipped.
+func MarshalINI(w io.Writer, v any) error {
+	rv, ok := indirectStruct(v, false)
+	if !ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && reflectx.Deref(rv.Type()).Kind() == reflect.Struct {
+			return nil
+		}
+		return pkgerrors.Errorf("marshal ini need struct value, but got %T", v)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := marshalINISection(buf, rv, ""); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// isINISection reports whether values of typ are mapped to sections
+func isINISection(typ reflect.Type) bool {
+	typ = reflectx.Deref(typ)
+	return DefaultTextCodec.isNestedStruct(typ) || isINIMap(typ)
+}
+
+// isINIMap reports whether typ is map of scalar values
+func isINIMap(typ reflect.Type) bool {
+	return isTextMap(typ) && !isFlattenComposite(DefaultTextCodec, typ.Elem())
+}
+
+type iniDecoder struct {
+	l    *lineReader
+	root reflect.Value
+	// name and value of current section, value is invalid when section unknown
+	name    string
+	section reflect.Value
+	// occurrences of list keys
+	counts map[string]int
+}
+
+func (d *iniDecoder) decode() error {
+	for {
+		line, err := d.l.readLine()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		text := strings.TrimSpace(line)
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+
+		if text[0] == '[' {
+			end := strings.IndexByte(text, ']')
+			if end < 0 {
+				return d.l.errorf("missing ']' in %q", text)
+			}
+			if tail := strings.TrimSpace(text[end+1:]); tail != "" && tail[0] != ';' && tail[0] != '#' {
+				return d.l.errorf("unexpected %q after section", tail)
+			}
+
+			d.name = strings.TrimSpace(text[1:end])
+			if d.name == "" {
+				return d.l.errorf("empty section name")
+			}
+			d.section = lookupINISection(d.root, d.name)
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return d.l.errorf("missing '=' in %q", text)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return d.l.errorf("empty key")
+		}
+
+		value, err = unquoteINIValue(strings.TrimSpace(value))
+		if err != nil {
+			return d.l.errorf("invalid value of %s: %s", key, err)
+		}
+
+		if err := d.set(key, value); err != nil {
+			return &SyntaxError{Line: d.l.line, Err: err}
+		}
+	}
+}
+
+// lookupINISection returns struct or map value of section name under struct rv.
+// nil pointers are only allocated when section found.
+func lookupINISection(rv reflect.Value, name string) (section reflect.Value) {
+	_ = eachStructField(rv, TagINI, true, func(field types.StructField, fieldName string, omitempty bool, fv reflect.Value) error {
+		if section.IsValid() || !isINISection(fv.Type()) {
+			return nil
+		}
+
+		switch {
+		case name == fieldName:
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			section = fv
+		case strings.HasPrefix(name, fieldName+".") && DefaultTextCodec.isNestedStruct(fv.Type()):
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				nv := reflectx.New(fv.Type())
+				if section = lookupINISection(reflectx.Indirect(nv), name[len(fieldName)+1:]); section.IsValid() {
+					fv.Set(nv)
+				}
+				return nil
+			}
+			section = lookupINISection(reflectx.Indirect(fv), name[len(fieldName)+1:])
+		}
+		return nil
+	})
+	return
+}
+
+func (d *iniDecoder) set(key string, value string) error {
+	if !d.section.IsValid() {
+		return nil
+	}
+
+	if d.section.Kind() == reflect.Map {
+		return unmarshalINIMapEntry(d.section, key, value, flattenKey(d.name, key))
+	}
+
+	return eachStructField(d.section, TagINI, true, func(field types.StructField, fieldName string, omitempty bool, fv reflect.Value) error {
+		if fieldName != key || isINISection(fv.Type()) {
+			return nil
+		}
+
+		path := flattenKey(d.name, key)
+
+		tc, err := DefaultTextCodec.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "unmarshal ini %s", path)
+		}
+
+		if !isTextList(reflectx.Deref(fv.Type())) {
+			if err := tc.UnmarshalText(fv, []byte(value)); err != nil {
+				return pkgerrors.Wrapf(err, "unmarshal ini %s", path)
+			}
+			return nil
+		}
+
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+
+		// existing elements are replaced by the first occurrence of key
+		n := d.counts[path]
+		d.counts[path]++
+
+		var elem reflect.Value
+
+		switch fv.Kind() {
+		case reflect.Slice:
+			if n == 0 {
+				fv.Set(reflect.MakeSlice(fv.Type(), 0, 1))
+			}
+			fv.Set(reflect.Append(fv, reflect.Zero(fv.Type().Elem())))
+			elem = fv.Index(n)
+		case reflect.Array:
+			if n >= fv.Len() {
+				return pkgerrors.Errorf("unmarshal ini %s: got %d values, but array length is %d", path, n+1, fv.Len())
+			}
+			if n == 0 {
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+			elem = fv.Index(n)
+		}
+
+		if err := tc.UnmarshalText(elem, []byte(value)); err != nil {
+			return pkgerrors.Wrapf(err, "unmarshal ini %s", path)
+		}
+		return nil
+	})
+}
+
+func unmarshalINIMapEntry(mv reflect.Value, key string, value string, path string) error {
+	if mv.IsNil() {
+		mv.Set(reflect.MakeMap(mv.Type()))
+	}
+
+	k := reflect.New(mv.Type().Key()).Elem()
+	if err := DefaultTextCodec.UnmarshalText(k, []byte(key)); err != nil {
+		return pkgerrors.Wrapf(err, "unmarshal ini %s", path)
+	}
+
+	v := reflect.New(mv.Type().Elem()).Elem()
+	if err := DefaultTextCodec.UnmarshalText(v, []byte(value)); err != nil {
+		return pkgerrors.Wrapf(err, "unmarshal ini %s", path)
+	}
+
+	mv.SetMapIndex(k, v)
+	return nil
+}
+
+// unquoteINIValue unquotes double quoted value with Go escapes, or strips inline comment of bare value
+func unquoteINIValue(s string) (string, error) {
+	if s == "" || s[0] != '"' {
+		return strings.TrimSpace(stripInlineComment(s, ";#")), nil
+	}
+
+	end := closingQuote(s[1:], '"')
+	if end < 0 {
+		return "", pkgerrors.New("unterminated quoted value")
+	}
+	end += 2
+
+	if tail := strings.TrimSpace(s[end:]); tail != "" && tail[0] != ';' && tail[0] != '#' {
+		return "", pkgerrors.Errorf("unexpected %q after quoted value", tail)
+	}
+	return strconv.Unquote(s[:end])
+}
+
+// quoteINIValue quotes value when it could not be kept as-is in INI file
+func quoteINIValue(value string) string {
+	if strings.ContainsAny(value, "\";#") || strings.TrimSpace(value) != value || strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func writeINIEntry(buf *bytes.Buffer, key string, value string, path string) error {
+	if key == "" || strings.TrimSpace(key) != key || strings.ContainsAny(key, "=;#[\"") || strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		return pkgerrors.Errorf("marshal ini %s: invalid key %q", path, key)
+	}
+	buf.WriteString(key)
+	buf.WriteString(" = ")
+	buf.WriteString(quoteINIValue(value))
+	buf.WriteByte('\n')
+	return nil
+}
+
+func writeINISectionHeader(buf *bytes.Buffer, name string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(buf, "[%s]\n", name)
+}
+
+func marshalINISection(buf *bytes.Buffer, rv reflect.Value, name string) error {
+	if name != "" {
+		writeINISectionHeader(buf, name)
+	}
+
+	type section struct {
+		name string
+		rv   reflect.Value
+	}
+
+	sections := make([]section, 0)
+
+	err := eachStructField(rv, TagINI, false, func(field types.StructField, fieldName string, omitempty bool, fv reflect.Value) error {
+		path := flattenKey(name, fieldName)
+
+		if isINISection(fv.Type()) {
+			if !isNil(fv) {
+				sections = append(sections, section{name: path, rv: reflectx.Indirect(fv)})
+			}
+			return nil
+		}
+
+		if omitempty && reflectx.IsEmptyValue(fv) {
+			return nil
+		}
+
+		tc, err := DefaultTextCodec.ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "marshal ini %s", path)
+		}
+
+		if tc.skipNull(fv) {
+			return nil
+		}
+
+		values := []reflect.Value{fv}
+		if fv = reflectx.Indirect(fv); isTextList(fv.Type()) {
+			values = make([]reflect.Value, fv.Len())
+			for i := range values {
+				values[i] = fv.Index(i)
+			}
+		}
+
+		for _, v := range values {
+			text, err := tc.MarshalText(v)
+			if err != nil {
+				return pkgerrors.Wrapf(err, "marshal ini %s", path)
+			}
+			if err := writeINIEntry(buf, fieldName, string(text), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sections {
+		if s.rv.Kind() == reflect.Map {
+			if s.rv.IsNil() {
+				continue
+			}
+			if err := marshalINIMap(buf, s.rv, s.name); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := marshalINISection(buf, s.rv, s.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalINIMap(buf *bytes.Buffer, rv reflect.Value, name string) error {
+	type entry struct {
+		key   string
+		value string
+	}
+
+	entries := make([]entry, 0, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := DefaultTextCodec.MarshalText(iter.Key())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "marshal ini %s", name)
+		}
+		v, err := DefaultTextCodec.MarshalText(iter.Value())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "marshal ini %s", flattenKey(name, string(k)))
+		}
+		entries = append(entries, entry{key: string(k), value: string(v)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	writeINISectionHeader(buf, name)
+	for _, e := range entries {
+		if err := writeINIEntry(buf, e.key, e.value, flattenKey(name, e.key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type INIPool struct {
+	Size    int           `ini:"size"`
+	Timeout time.Duration `ini:"timeout"`
+}
+
+type INIDatabase struct {
+	Host     string   `ini:"host"`
+	Port     int      `ini:"port"`
+	Replicas []string `ini:"replica"`
+	Pool     *INIPool `ini:"pool"`
+}
+
+type INIConfig struct {
+	Name     string            `ini:"name"`
+	Debug    bool              `ini:"debug,omitempty"`
+	Ports    [2]int            `ini:"port"`
+	Database INIDatabase       `ini:"database"`
+	Cache    *INIDatabase      `ini:"cache"`
+	Labels   map[string]string `ini:"labels"`
+}
+
+const iniFile = `; default section
+name = "srv; main"
+port = 80
+port = 443
+
+[database] ; primary
+host = localhost # inline comment
+port=5432
+replica = r1
+replica = r2
+unknown = ignored
+
+[database.pool]
+size = 10
+timeout = 1s
+
+[unknown]
+host = ignored
+
+[labels]
+app = srv
+tier = " backend "
+`
+
+func TestUnmarshalINI(t *testing.T) {
+	c := INIConfig{
+		Database: INIDatabase{Replicas: []string{"old"}},
+	}
+
+	err := UnmarshalINI(strings.NewReader(iniFile), &c)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(INIConfig{
+		Name:  "srv; main",
+		Ports: [2]int{80, 443},
+		Database: INIDatabase{
+			Host:     "localhost",
+			Port:     5432,
+			Replicas: []string{"r1", "r2"},
+			Pool:     &INIPool{Size: 10, Timeout: time.Second},
+		},
+		Labels: map[string]string{"app": "srv", "tier": " backend "},
+	}))
+
+	t.Run("errors with line", func(t *testing.T) {
+		for input, line := range map[string]int{
+			"name = a\n[database\n":              2,
+			"name = a\n\n[]\n":                   3,
+			"[database]\nhost\n":                 2,
+			"[database]\nport = x\n":             2,
+			"port = 1\nport = 2\nport = 3\n":     3,
+			"[database]\nhost = \"localhost\n":   2,
+			"[database]\nhost = \"a\" b\n":       2,
+			"[database.pool]\ntimeout = 1 day\n": 2,
+		} {
+			err := UnmarshalINI(strings.NewReader(input), &INIConfig{})
+
+			syntaxErr := &SyntaxError{}
+			NewWithT(t).Expect(errors.As(err, &syntaxErr)).To(BeTrue(), input)
+			NewWithT(t).Expect(syntaxErr.Line).To(Equal(line), input)
+		}
+	})
+}
+
+func TestMarshalINI(t *testing.T) {
+	c := INIConfig{
+		Name:  "srv; main",
+		Ports: [2]int{80, 443},
+		Database: INIDatabase{
+			Host:     "localhost",
+			Port:     5432,
+			Replicas: []string{"r1", "r2"},
+			Pool:     &INIPool{Size: 10, Timeout: time.Second},
+		},
+		Labels: map[string]string{"tier": " backend ", "app": "srv"},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	NewWithT(t).Expect(MarshalINI(buf, c)).To(BeNil())
+	NewWithT(t).Expect(buf.String()).To(Equal(`name = "srv; main"
+port = 80
+port = 443
+
+[database]
+host = localhost
+port = 5432
+replica = r1
+replica = r2
+
+[database.pool]
+size = 10
+timeout = 1s
+
+[labels]
+app = srv
+tier = " backend "
+`))
+
+	c2 := INIConfig{}
+	NewWithT(t).Expect(UnmarshalINI(bytes.NewReader(buf.Bytes()), &c2)).To(BeNil())
+	NewWithT(t).Expect(c2).To(Equal(c))
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := MarshalINI(bytes.NewBuffer(nil), INIConfig{Labels: map[string]string{"a=b": "c"}})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
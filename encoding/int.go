@@ -0,0 +1,100 @@
+package encoding
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func parseIntBase(s string) (int, error) {
+	switch s {
+	case "0", "2", "8", "10", "16":
+		return strconv.Atoi(s)
+	}
+	return 0, fmt.Errorf("unsupported int base %q", s)
+}
+
+// intPrefix returns prefix of literals in base, base 10 or unsupported base has no prefix
+func intPrefix(base int) string {
+	switch base {
+	case 2:
+		return "0b"
+	case 8:
+		return "0o"
+	case 16:
+		return "0x"
+	}
+	return ""
+}
+
+func (c *TextCodec) appendInt(dst []byte, i int64) []byte {
+	prefix := intPrefix(c.intBase)
+	if prefix == "" {
+		return strconv.AppendInt(dst, i, 10)
+	}
+	u := uint64(i)
+	if i < 0 {
+		dst = append(dst, '-')
+		u = -u
+	}
+	return strconv.AppendUint(append(dst, prefix...), u, c.intBase)
+}
+
+func (c *TextCodec) appendUint(dst []byte, u uint64) []byte {
+	prefix := intPrefix(c.intBase)
+	if prefix == "" {
+		return strconv.AppendUint(dst, u, 10)
+	}
+	return strconv.AppendUint(append(dst, prefix...), u, c.intBase)
+}
+
+// parseInt parses decimal when base is 10, and Go literals when base is 0.
+// With base 2, 8 or 16, digits without prefix are in the base, and literals with prefix are accepted too.
+func (c *TextCodec) parseInt(data []byte, bitSize int) (int64, error) {
+	if c.intBase == 10 {
+		return strconv.ParseInt(string(data), 10, bitSize)
+	}
+	return strconv.ParseInt(c.intLiteral(data), 0, bitSize)
+}
+
+func (c *TextCodec) parseUint(data []byte, bitSize int) (uint64, error) {
+	if c.intBase == 10 {
+		return strconv.ParseUint(string(data), 10, bitSize)
+	}
+	return strconv.ParseUint(c.intLiteral(data), 0, bitSize)
+}
+
+// intLiteral returns data as Go literal, prefix of base is added to digits without prefix.
+// underscores are checked as Go literals.
+func (c *TextCodec) intLiteral(data []byte) string {
+	s := string(data)
+
+	prefix := intPrefix(c.intBase)
+	if prefix == "" {
+		return s
+	}
+
+	sign := ""
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+
+	if hasIntPrefix(s, c.intBase) {
+		return sign + s
+	}
+	return sign + prefix + s
+}
+
+// hasIntPrefix reports whether s starts with prefix of Go literals,
+// 0b is digits in base 16.
+func hasIntPrefix(s string, base int) bool {
+	if len(s) < 2 || s[0] != '0' {
+		return false
+	}
+	switch s[1] {
+	case 'x', 'X', 'o', 'O':
+		return true
+	case 'b', 'B':
+		return base != 16
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestTextCodecIntBase(t *testing.T) {
+	t.Run("decode literals", func(t *testing.T) {
+		c := NewTextCodec(WithIntBase(0))
+
+		cases := map[string]int64{
+			"0x1F":      31,
+			"0o755":     493,
+			"0755":      493,
+			"0b1010":    10,
+			"1_000_000": 1000000,
+			"-0x10":     -16,
+			"42":        42,
+		}
+
+		for text, expect := range cases {
+			var i int64
+			NewWithT(t).Expect(c.UnmarshalText(&i, []byte(text))).To(BeNil())
+			NewWithT(t).Expect(i).To(Equal(expect))
+		}
+
+		var u uint16
+		NewWithT(t).Expect(c.UnmarshalText(&u, []byte("0xFFFF"))).To(BeNil())
+		NewWithT(t).Expect(u).To(Equal(uint16(0xFFFF)))
+		NewWithT(t).Expect(c.UnmarshalText(&u, []byte("0x1_0000"))).NotTo(BeNil())
+
+		text, err := c.MarshalText(31)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("31"))
+	})
+
+	t.Run("decimal by default", func(t *testing.T) {
+		var i int
+		NewWithT(t).Expect(UnmarshalText(&i, []byte("0x1F"))).NotTo(BeNil())
+		NewWithT(t).Expect(UnmarshalText(&i, []byte("1_000"))).NotTo(BeNil())
+	})
+
+	t.Run("encode with prefix", func(t *testing.T) {
+		cases := []struct {
+			base   int
+			v      any
+			expect string
+		}{
+			{16, 31, "0x1f"},
+			{16, -31, "-0x1f"},
+			{8, uint32(0o755), "0o755"},
+			{2, NamedInt8(-128), "-0b10000000"},
+			{16, []uint8{1, 255}, "Af8="},
+			{16, []uint16{1, 255}, "0x1,0xff"},
+		}
+
+		for _, c := range cases {
+			tc := NewTextCodec(WithIntBase(c.base))
+
+			text, err := tc.MarshalText(c.v)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(c.expect))
+		}
+
+		var i NamedInt8
+		NewWithT(t).Expect(NewTextCodec(WithIntBase(2)).UnmarshalText(&i, []byte("-0b10000000"))).To(BeNil())
+		NewWithT(t).Expect(i).To(Equal(NamedInt8(-128)))
+	})
+
+	t.Run("decode digits in base", func(t *testing.T) {
+		cases := []struct {
+			base   int
+			text   string
+			expect int64
+		}{
+			{8, "755", 0o755},
+			{8, "0755", 0o755},
+			{8, "0o755", 0o755},
+			{8, "0x1F", 31},
+			{8, "-17", -0o17},
+			{8, "7_7", 0o77},
+			{16, "ff", 0xff},
+			{16, "-FF", -0xff},
+			{16, "0b1", 0xb1},
+			{16, "0x1F", 31},
+			{2, "1010", 10},
+			{2, "0o17", 15},
+		}
+
+		for _, c := range cases {
+			var i int64
+			NewWithT(t).Expect(NewTextCodec(WithIntBase(c.base)).UnmarshalText(&i, []byte(c.text))).To(BeNil())
+			NewWithT(t).Expect(i).To(Equal(c.expect), "%s of base %d", c.text, c.base)
+		}
+
+		var u uint8
+		NewWithT(t).Expect(NewTextCodec(WithIntBase(16)).UnmarshalText(&u, []byte("ff"))).To(BeNil())
+		NewWithT(t).Expect(u).To(Equal(uint8(0xff)))
+
+		for _, text := range []string{"8", "0x", "", "7__7", "-"} {
+			var i int
+			NewWithT(t).Expect(NewTextCodec(WithIntBase(8)).UnmarshalText(&i, []byte(text))).NotTo(BeNil(), text)
+		}
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		v := struct {
+			Mode uint32 `text:"base=8"`
+			Size int    `text:"base=0"`
+		}{}
+
+		values := map[string][]string{
+			"Mode": {"0o644"},
+			"Size": {"1_024"},
+		}
+		NewWithT(t).Expect(UnmarshalForm(values, &v)).To(BeNil())
+		NewWithT(t).Expect(v.Mode).To(Equal(uint32(0o644)))
+		NewWithT(t).Expect(v.Size).To(Equal(1024))
+
+		unprefixed := v
+		NewWithT(t).Expect(UnmarshalForm(map[string][]string{"Mode": {"755"}}, &unprefixed)).To(BeNil())
+		NewWithT(t).Expect(unprefixed.Mode).To(Equal(uint32(0o755)))
+
+		form, err := MarshalForm(v)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(form.Get("Mode")).To(Equal("0o644"))
+		NewWithT(t).Expect(form.Get("Size")).To(Equal("1024"))
+
+		_, err = DefaultTextCodec.ForField(`text:"base=3"`)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,573 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagJSON is the struct tag for JSON keys.
+const TagJSON = "json"
+
+// JSONCodec converts values to and from JSON, with fields resolved by types.EachField,
+// so the JSON matches the schemas generated from the same types.Type.
+//
+// Unlike encoding/json,
+// omitempty goes through reflect.IsEmptyValue (ZeroChecker included),
+// and scalars which only implement TextMarshaler or registered to text codec, keys of maps as well,
+// are converted by TextCodec as JSON strings.
+// Values implement json.Marshaler or json.Unmarshaler are still converted by themselves,
+// except registered types and time.Time, which follow options of TextCodec like layout.
+type JSONCodec struct {
+	// Tag for field names, TagJSON when empty
+	Tag string
+	// Text converts text values, DefaultTextCodec when nil
+	Text *TextCodec
+}
+
+var DefaultJSONCodec = JSONCodec{Tag: TagJSON}
+
+func MarshalJSON(v any) ([]byte, error) {
+	return DefaultJSONCodec.Marshal(v)
+}
+
+func UnmarshalJSON(data []byte, v any) error {
+	return DefaultJSONCodec.Unmarshal(data, v)
+}
+
+func (c JSONCodec) tag() string {
+	if c.Tag == "" {
+		return TagJSON
+	}
+	return c.Tag
+}
+
+func (c JSONCodec) textCodec() *TextCodec {
+	if c.Text == nil {
+		return DefaultTextCodec
+	}
+	return c.Text
+}
+
+func (c JSONCodec) Marshal(v any) ([]byte, error) {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+
+	data, err := c.appendValue(nil, c.textCodec(), rv)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "marshal json")
+	}
+	return data, nil
+}
+
+func (c JSONCodec) Unmarshal(data []byte, v any) error {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return pkgerrors.Errorf("unmarshal json need non-nil ptr value, but got %T", v)
+		}
+		rv = rv.Elem()
+	}
+
+	// syntax errors as *json.SyntaxError with offset
+	if !json.Valid(data) {
+		var raw json.RawMessage
+		return json.Unmarshal(data, &raw)
+	}
+
+	if err := c.unmarshalValue(c.textCodec(), rv, bytes.TrimSpace(data)); err != nil {
+		return pkgerrors.Wrap(err, "unmarshal json")
+	}
+	return nil
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// preferText reports whether values of typ are converted by text codec, even if json (un)marshaler
+func (c *TextCodec) preferText(typ reflect.Type) bool {
+	return typ == timeType || c.registry.has(typ)
+}
+
+// marshalsJSONText reports whether values of typ are encoded as JSON strings by text codec
+func (c *TextCodec) marshalsJSONText(typ reflect.Type) bool {
+	if c.preferText(typ) || reflectx.IsBytes(typ) {
+		return true
+	}
+	for _, t := range []reflect.Type{typ, reflect.PtrTo(typ)} {
+		if t.Implements(textMarshalerType) || t.Implements(textAppenderType) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalsJSONText reports whether values of typ are decoded from JSON strings by text codec
+func (c *TextCodec) unmarshalsJSONText(typ reflect.Type) bool {
+	return c.preferText(typ) || reflectx.IsBytes(typ) || reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+func jsonMarshalerOf(rv reflect.Value) (json.Marshaler, bool) {
+	if rv.Type().Implements(jsonMarshalerType) {
+		return rv.Interface().(json.Marshaler), true
+	}
+	if rv.CanAddr() && reflect.PtrTo(rv.Type()).Implements(jsonMarshalerType) {
+		return rv.Addr().Interface().(json.Marshaler), true
+	}
+	return nil, false
+}
+
+func (c JSONCodec) appendValue(dst []byte, tc *TextCodec, rv reflect.Value) ([]byte, error) {
+	if !rv.IsValid() {
+		return append(dst, "null"...), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return append(dst, "null"...), nil
+		}
+	}
+
+	if m, ok := jsonMarshalerOf(rv); ok && !tc.preferText(rv.Type()) {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		buf := bytes.NewBuffer(dst)
+		if err := json.Compact(buf, data); err != nil {
+			return nil, pkgerrors.Wrapf(err, "json of %s", rv.Type())
+		}
+		return buf.Bytes(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return c.appendValue(dst, tc, rv.Elem())
+	}
+
+	if tc.marshalsJSONText(rv.Type()) {
+		if !rv.CanAddr() {
+			// for MarshalText of ptr receiver
+			addressable := reflect.New(rv.Type()).Elem()
+			addressable.Set(rv)
+			rv = addressable
+		}
+		text, err := tc.MarshalText(rv)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst, string(text)), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(dst, rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(dst, rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(dst, rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return appendJSONFloat(dst, rv.Float(), rv.Type().Bits())
+	case reflect.String:
+		return appendJSONString(dst, rv.String()), nil
+	case reflect.Struct:
+		return c.appendStruct(dst, rv)
+	case reflect.Map:
+		return c.appendMap(dst, tc, rv)
+	case reflect.Slice, reflect.Array:
+		dst = append(dst, '[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = c.appendValue(dst, tc, rv.Index(i)); err != nil {
+				return nil, pkgerrors.Wrapf(err, "%d", i)
+			}
+		}
+		return append(dst, ']'), nil
+	}
+
+	// others like complex as text
+	text, err := tc.MarshalText(rv)
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONString(dst, string(text)), nil
+}
+
+func (c JSONCodec) appendStruct(dst []byte, rv reflect.Value) ([]byte, error) {
+	dst = append(dst, '{')
+	n := 0
+
+	err := eachStructField(rv, c.tag(), false, func(field types.StructField, name string, omitempty bool, fv reflect.Value) (err error) {
+		if omitempty && reflectx.IsEmptyValue(fv) {
+			return nil
+		}
+
+		tc, err := c.textCodec().ForField(field.Tag())
+		if err != nil {
+			return pkgerrors.Wrapf(err, "%s", name)
+		}
+
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		n++
+
+		dst = append(appendJSONString(dst, name), ':')
+		if dst, err = c.appendValue(dst, tc, fv); err != nil {
+			return pkgerrors.Wrapf(err, "%s", name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(dst, '}'), nil
+}
+
+func (c JSONCodec) appendMap(dst []byte, tc *TextCodec, rv reflect.Value) ([]byte, error) {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		key, err := tc.MarshalText(iter.Key())
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "key %v", iter.Key())
+		}
+		entries = append(entries, entry{key: string(key), value: iter.Value()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	dst = append(dst, '{')
+	for i, e := range entries {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(appendJSONString(dst, e.key), ':')
+
+		var err error
+		if dst, err = c.appendValue(dst, tc, e.value); err != nil {
+			return nil, pkgerrors.Wrapf(err, "%s", e.key)
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// unmarshalValue decodes valid JSON value data into addressable rv
+func (c JSONCodec) unmarshalValue(tc *TextCodec, rv reflect.Value, data []byte) error {
+	if string(data) == "null" {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return c.unmarshalValue(tc, rv.Elem(), data)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return pkgerrors.Errorf("unmarshal json into non-empty interface %s is not supported", rv.Type())
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	if reflect.PtrTo(rv.Type()).Implements(jsonUnmarshalerType) && !tc.preferText(rv.Type()) {
+		return rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data)
+	}
+
+	if tc.unmarshalsJSONText(rv.Type()) || !isJSONKind(rv.Kind()) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need string", data, rv.Type())
+		}
+		return tc.UnmarshalText(rv, []byte(text))
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(data))
+		if err != nil || data[0] == '"' {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need bool", data, rv.Type())
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(data), 10, rv.Type().Bits())
+		if err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need integer", data, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(string(data), 10, rv.Type().Bits())
+		if err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need unsigned integer", data, rv.Type())
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if data[0] != '-' && (data[0] < '0' || data[0] > '9') {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need number", data, rv.Type())
+		}
+		f, err := strconv.ParseFloat(string(data), rv.Type().Bits())
+		if err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need number", data, rv.Type())
+		}
+		rv.SetFloat(f)
+	case reflect.String:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need string", data, rv.Type())
+		}
+		rv.SetString(s)
+	case reflect.Struct:
+		return c.unmarshalStruct(rv, data)
+	case reflect.Map:
+		return c.unmarshalMap(tc, rv, data)
+	case reflect.Slice, reflect.Array:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return pkgerrors.Errorf("unmarshal json %s into %s, need array", data, rv.Type())
+		}
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), len(list), len(list)))
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(list) {
+				rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+				continue
+			}
+			if err := c.unmarshalValue(tc, rv.Index(i), list[i]); err != nil {
+				return pkgerrors.Wrapf(err, "%d", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isJSONKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// unmarshalStruct sets fields found in JSON object, unknown keys are ignored.
+// nil embedded ptr is only allocated when fields under it found.
+func (c JSONCodec) unmarshalStruct(rv reflect.Value, data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return pkgerrors.Errorf("unmarshal json %s into %s, need object", data, rv.Type())
+	}
+
+	var err error
+
+	walkStructFields(rv.Type(), c.tag(), nil, func(field types.StructField, name string, omitempty bool, index []int) bool {
+		raw, ok := object[name]
+		if !ok {
+			return true
+		}
+
+		fv, ok := fieldByIndex(rv, index, true)
+		if !ok {
+			return true
+		}
+
+		tc, e := c.textCodec().ForField(field.Tag())
+		if e == nil {
+			e = c.unmarshalValue(tc, fv, raw)
+		}
+		if e != nil {
+			err = pkgerrors.Wrapf(e, "%s", name)
+			return false
+		}
+		return true
+	})
+
+	return err
+}
+
+func (c JSONCodec) unmarshalMap(tc *TextCodec, rv reflect.Value, data []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return pkgerrors.Errorf("unmarshal json %s into %s, need object", data, rv.Type())
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(object)))
+	}
+
+	for key, raw := range object {
+		kv := reflect.New(rv.Type().Key()).Elem()
+		if err := tc.UnmarshalText(kv, []byte(key)); err != nil {
+			return pkgerrors.Wrapf(err, "key %s", key)
+		}
+
+		ev := reflect.New(rv.Type().Elem()).Elem()
+		if err := c.unmarshalValue(tc, ev, raw); err != nil {
+			return pkgerrors.Wrapf(err, "%s", key)
+		}
+
+		rv.SetMapIndex(kv, ev)
+	}
+
+	return nil
+}
+
+func appendJSONFloat(dst []byte, f float64, bitSize int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, pkgerrors.Errorf("unsupported float value %v", f)
+	}
+
+	// same as encoding/json, exponent format for too large or small values
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) || bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	dst = strconv.AppendFloat(dst, f, format, -1, bitSize)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst, nil
+}
+
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				dst = append(dst, '\\', b)
+			case b == '\n':
+				dst = append(dst, '\\', 'n')
+			case b == '\r':
+				dst = append(dst, '\\', 'r')
+			case b == '\t':
+				dst = append(dst, '\\', 't')
+			case b < 0x20:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			default:
+				dst = append(dst, b)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			// line separators break JavaScript
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+
+	return append(dst, '"')
+}
+
+// JSONStreamOption sets options of JSONEncoder and JSONDecoder
+type JSONStreamOption func(c *JSONCodec)
+
+// WithJSONCodec sets JSONCodec of JSONEncoder and JSONDecoder. default DefaultJSONCodec
+func WithJSONCodec(c JSONCodec) JSONStreamOption {
+	return func(x *JSONCodec) {
+		*x = c
+	}
+}
+
+func newJSONStreamCodec(opts []JSONStreamOption) JSONCodec {
+	c := DefaultJSONCodec
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// JSONEncoder writes JSON values, one per line
+type JSONEncoder struct {
+	w     io.Writer
+	codec JSONCodec
+}
+
+func NewJSONEncoder(w io.Writer, opts ...JSONStreamOption) *JSONEncoder {
+	return &JSONEncoder{w: w, codec: newJSONStreamCodec(opts)}
+}
+
+// Encode writes v as a line, like JSONCodec.Marshal
+func (e *JSONEncoder) Encode(v any) error {
+	data, err := e.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// JSONDecoder reads JSON values from stream
+type JSONDecoder struct {
+	d     *json.Decoder
+	codec JSONCodec
+}
+
+func NewJSONDecoder(r io.Reader, opts ...JSONStreamOption) *JSONDecoder {
+	return &JSONDecoder{d: json.NewDecoder(r), codec: newJSONStreamCodec(opts)}
+}
+
+// Decode decodes next JSON value into v, like JSONCodec.Unmarshal.
+// io.EOF is returned when no more values.
+func (d *JSONDecoder) Decode(v any) error {
+	var raw json.RawMessage
+	if err := d.d.Decode(&raw); err != nil {
+		return err
+	}
+	return d.codec.Unmarshal(raw, v)
+}
@@ -0,0 +1,275 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/types"
+)
+
+type JSONVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+func (v JSONVersion) IsZero() bool {
+	return v.Major == 0 && v.Minor == 0
+}
+
+type JSONBase struct {
+	ID int `json:"id"`
+}
+
+type JSONAudit struct {
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+type JSONMeta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type JSONResource struct {
+	JSONBase
+	*JSONAudit
+	JSONMeta  `json:"meta"`
+	Name      string            `json:"name"`
+	Version   JSONVersion       `json:"version,omitempty"`
+	CreatedAt time.Time         `json:"createdAt,omitempty"`
+	Timeout   Duration          `json:"timeout"`
+	Price     Decimal           `json:"price"`
+	Counts    map[NamedInt]uint `json:"counts,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	Ratio     *float64          `json:"ratio"`
+	Extra     json.RawMessage   `json:"extra,omitempty"`
+	Any       any               `json:"any,omitempty"`
+	Ignored   string            `json:"-"`
+}
+
+type JSONUnmarshalOnly struct {
+	V string
+}
+
+func (u *JSONUnmarshalOnly) UnmarshalText(data []byte) error {
+	u.V = string(data)
+	return nil
+}
+
+type JSONInner struct {
+	N    int    `json:"n"`
+	Name string `json:"innerName"`
+}
+
+type JSONOuter struct {
+	JSONInner
+	Name string `json:"name"`
+	S    string `json:"s"`
+}
+
+func TestJSONCodec(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		data, err := MarshalJSON(JSONResource{
+			JSONBase: JSONBase{ID: 1},
+			JSONMeta: JSONMeta{Labels: map[string]string{"b": "2", "a": "1"}},
+			Name:     "x<y>\n",
+			Timeout:  Duration(time.Second),
+			Price:    Decimal{Int: 15, Exp: -1},
+			Counts:   map[NamedInt]uint{2: 20, 1: 10},
+			Extra:    json.RawMessage(`{ "k" : [1, 2] }`),
+		})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(
+			`{"id":1,"meta":{"labels":{"a":"1","b":"2"}},"name":"x<y>\n","timeout":"1s","price":"15e-1","counts":{"1":10,"2":20},"ratio":null,"extra":{"k":[1,2]}}`,
+		))
+	})
+
+	t.Run("OmitemptyByZeroChecker", func(t *testing.T) {
+		data, err := MarshalJSON(struct {
+			Version   JSONVersion `json:"version,omitempty"`
+			CreatedAt time.Time   `json:"createdAt,omitempty"`
+		}{})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(`{}`))
+
+		// encoding/json keeps them
+		std, _ := json.Marshal(struct {
+			CreatedAt time.Time `json:"createdAt,omitempty"`
+		}{})
+		NewWithT(t).Expect(string(std)).To(Equal(`{"createdAt":"0001-01-01T00:00:00Z"}`))
+	})
+
+	t.Run("FieldsMatchEachField", func(t *testing.T) {
+		data, err := MarshalJSON(JSONResource{
+			JSONAudit: &JSONAudit{CreatedBy: "admin"},
+			Data:      []byte("x"),
+		})
+		NewWithT(t).Expect(err).To(BeNil())
+
+		object := map[string]json.RawMessage{}
+		NewWithT(t).Expect(json.Unmarshal(data, &object)).To(BeNil())
+
+		names := make([]string, 0)
+		types.EachField(types.FromRType(reflect.TypeOf(JSONResource{})), TagJSON, func(field types.StructField, name string, omitempty bool) bool {
+			if _, ok := object[name]; ok {
+				names = append(names, name)
+			}
+			return true
+		})
+		NewWithT(t).Expect(names).To(HaveLen(len(object)))
+		NewWithT(t).Expect(names).To(ContainElements("id", "createdBy", "meta", "data"))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		ratio := 0.5
+		r := JSONResource{
+			JSONBase:  JSONBase{ID: 1},
+			JSONAudit: &JSONAudit{CreatedBy: "admin"},
+			JSONMeta:  JSONMeta{Labels: map[string]string{"a": "1"}},
+			Name:      "\"quoted\"   \x01 中文",
+			Version:   JSONVersion{Major: 1},
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+			Timeout:   Duration(time.Minute),
+			Price:     Decimal{Int: 15, Exp: -1},
+			Counts:    map[NamedInt]uint{1: 10},
+			Data:      []byte{0, 1, 2},
+			Ratio:     &ratio,
+			Extra:     json.RawMessage(`{"k":1}`),
+			Any:       map[string]any{"a": []any{1.5, "x", true}},
+		}
+
+		data, err := MarshalJSON(r)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(json.Valid(data)).To(BeTrue())
+
+		decoded := JSONResource{}
+		NewWithT(t).Expect(UnmarshalJSON(data, &decoded)).To(BeNil())
+		NewWithT(t).Expect(decoded).To(Equal(r))
+	})
+
+	t.Run("Floats", func(t *testing.T) {
+		for _, f := range []float64{0, 1, -1.5, 1e-7, 1e21, 123456789.125} {
+			data, err := MarshalJSON(f)
+			NewWithT(t).Expect(err).To(BeNil())
+
+			std, _ := json.Marshal(f)
+			NewWithT(t).Expect(string(data)).To(Equal(string(std)))
+		}
+
+		_, err := MarshalJSON(struct{ F float32 }{F: float32(0.1)})
+		NewWithT(t).Expect(err).To(BeNil())
+	})
+
+	t.Run("UnmarshalNullAndAbsent", func(t *testing.T) {
+		ratio := 1.0
+		r := JSONResource{Name: "keep", Ratio: &ratio, Counts: map[NamedInt]uint{1: 1}}
+		NewWithT(t).Expect(UnmarshalJSON([]byte(`{"ratio":null,"counts":null,"unknown":1}`), &r)).To(BeNil())
+		NewWithT(t).Expect(r.Name).To(Equal("keep"))
+		NewWithT(t).Expect(r.Ratio).To(BeNil())
+		NewWithT(t).Expect(r.Counts).To(BeNil())
+		// embedded ptr not allocated when no fields under it
+		NewWithT(t).Expect(r.JSONAudit).To(BeNil())
+	})
+
+	t.Run("CustomTagAndText", func(t *testing.T) {
+		c := JSONCodec{Tag: "form", Text: NewTextCodec(WithTimeLayout(time.DateOnly))}
+
+		v := struct {
+			Day  time.Time `form:"day"`
+			Name string
+		}{Day: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Name: "x"}
+
+		data, err := c.Marshal(v)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(`{"day":"2024-01-02","Name":"x"}`))
+	})
+
+	t.Run("EmbeddedFields", func(t *testing.T) {
+		o := JSONOuter{}
+		NewWithT(t).Expect(UnmarshalJSON([]byte(`{"name":"outer","innerName":"inner","s":"x"}`), &o)).To(BeNil())
+		NewWithT(t).Expect(o).To(Equal(JSONOuter{JSONInner: JSONInner{Name: "inner"}, Name: "outer", S: "x"}))
+
+		data, err := MarshalJSON(o)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(`{"n":0,"innerName":"inner","name":"outer","s":"x"}`))
+
+		err = UnmarshalJSON([]byte(`{"n":"bad","s":"x"}`), &JSONOuter{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("n"))
+	})
+
+	t.Run("TextUnmarshalerOnly", func(t *testing.T) {
+		v := struct {
+			T JSONUnmarshalOnly
+		}{}
+
+		data, err := MarshalJSON(v)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal(`{"T":{"V":""}}`))
+
+		NewWithT(t).Expect(UnmarshalJSON([]byte(`{"T":"x"}`), &v)).To(BeNil())
+		NewWithT(t).Expect(v.T.V).To(Equal("x"))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		r := JSONResource{}
+
+		err := UnmarshalJSON([]byte(`{"id":`), &r)
+		var syntaxErr *json.SyntaxError
+		NewWithT(t).Expect(err).To(BeAssignableToTypeOf(syntaxErr))
+
+		err = UnmarshalJSON([]byte(`{"id":"1"}`), &r)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("id"))
+
+		err = UnmarshalJSON([]byte(`{"timeout":"x"}`), &r)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("timeout"))
+
+		NewWithT(t).Expect(UnmarshalJSON([]byte(`{}`), r)).NotTo(BeNil())
+
+		_, err = MarshalJSON(struct{ F func() }{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		e := NewJSONEncoder(buf)
+		NewWithT(t).Expect(e.Encode(JSONBase{ID: 1})).To(BeNil())
+		NewWithT(t).Expect(e.Encode(JSONBase{ID: 2})).To(BeNil())
+		NewWithT(t).Expect(strings.Count(buf.String(), "\n")).To(Equal(2))
+
+		d := NewJSONDecoder(buf)
+		ids := make([]int, 0)
+		for {
+			b := JSONBase{}
+			err := d.Decode(&b)
+			if err == io.EOF {
+				break
+			}
+			NewWithT(t).Expect(err).To(BeNil())
+			ids = append(ids, b.ID)
+		}
+		NewWithT(t).Expect(ids).To(Equal([]int{1, 2}))
+	})
+
+	t.Run("Stream with codec", func(t *testing.T) {
+		c := JSONCodec{Tag: "kv"}
+
+		v := struct {
+			Name string `kv:"n"`
+		}{Name: "a"}
+
+		buf := bytes.NewBuffer(nil)
+		NewWithT(t).Expect(NewJSONEncoder(buf, WithJSONCodec(c)).Encode(v)).To(BeNil())
+		NewWithT(t).Expect(buf.String()).To(Equal("{\"n\":\"a\"}\n"))
+
+		v.Name = ""
+		NewWithT(t).Expect(NewJSONDecoder(buf, WithJSONCodec(c)).Decode(&v)).To(BeNil())
+		NewWithT(t).Expect(v.Name).To(Equal("a"))
+	})
+}
@@ -0,0 +1,157 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeyValueFile is parsed key/value file like .env or .properties,
+// which keeps comments, blank lines and order of keys,
+// so values could be updated without rewriting the whole file.
+type KeyValueFile struct {
+	chunks []*kvChunk
+	format func(key string, value string) string
+}
+
+// kvChunk is raw lines of one entry, or comment or blank line
+type kvChunk struct {
+	raw   string
+	entry bool
+	key   string
+	value string
+	// prefix kept when entry updated, like indent or `export `
+	prefix string
+	eol    string
+}
+
+type kvParser func(l *lineReader) (*kvChunk, error)
+
+func readKeyValueFile(r io.Reader, parse kvParser, format func(key string, value string) string) (*KeyValueFile, error) {
+	f := &KeyValueFile{format: format}
+	l := newLineReader(r)
+	for {
+		c, err := parse(l)
+		if err != nil {
+			if err == io.EOF {
+				return f, nil
+			}
+			return nil, err
+		}
+		f.chunks = append(f.chunks, c)
+	}
+}
+
+// Get returns value of key, the last one wins when key repeated
+func (f *KeyValueFile) Get(key string) (string, bool) {
+	if c := f.lookup(key); c != nil {
+		return c.value, true
+	}
+	return "", false
+}
+
+// Set updates value of key in place, or appends new entry at the end
+func (f *KeyValueFile) Set(key string, value string) {
+	if c := f.lookup(key); c != nil {
+		c.value = value
+		c.raw = c.prefix + f.format(key, value) + c.eol
+		return
+	}
+
+	if n := len(f.chunks); n > 0 && f.chunks[n-1].eol == "" {
+		f.chunks[n-1].eol = "\n"
+		f.chunks[n-1].raw += "\n"
+	}
+
+	f.chunks = append(f.chunks, &kvChunk{
+		raw:   f.format(key, value) + "\n",
+		entry: true,
+		key:   key,
+		value: value,
+		eol:   "\n",
+	})
+}
+
+// Keys returns keys in order of first appearance
+func (f *KeyValueFile) Keys() []string {
+	keys := make([]string, 0, len(f.chunks))
+	seen := map[string]bool{}
+	for _, c := range f.chunks {
+		if c.entry && !seen[c.key] {
+			seen[c.key] = true
+			keys = append(keys, c.key)
+		}
+	}
+	return keys
+}
+
+// Map returns all key/value pairs
+func (f *KeyValueFile) Map() map[string]string {
+	m := map[string]string{}
+	for _, c := range f.chunks {
+		if c.entry {
+			m[c.key] = c.value
+		}
+	}
+	return m
+}
+
+// WriteTo writes the file with all comments and blank lines kept
+func (f *KeyValueFile) WriteTo(w io.Writer) (int64, error) {
+	n := int64(0)
+	for _, c := range f.chunks {
+		i, err := io.WriteString(w, c.raw)
+		n += int64(i)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (f *KeyValueFile) lookup(key string) *kvChunk {
+	for i := len(f.chunks) - 1; i >= 0; i-- {
+		if c := f.chunks[i]; c.entry && c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+type lineReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+// readLine returns next line with line ending, io.EOF when no more lines
+func (l *lineReader) readLine() (string, error) {
+	s, err := l.r.ReadString('\n')
+	if err == io.EOF && s != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	l.line++
+	return s, nil
+}
+
+func (l *lineReader) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: l.line, Err: fmt.Errorf(format, args...)}
+}
+
+// cutEOL cuts line ending of line
+func cutEOL(line string) (string, string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
@@ -0,0 +1,92 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// eachEntry calls each with unescaped key and value of k=v entries in data
+func (c *TextCodec) eachEntry(data []byte, each func(key []byte, value []byte) error) error {
+	for _, part := range c.split(data, c.separator) {
+		kv := c.split(part, c.keyValueSeparator)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid map entry %q", part)
+		}
+		if err := each(c.unescape(kv[0]), c.unescape(kv[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeFrom escapes separators in dst[start:] in place
+func (c *TextCodec) escapeFrom(dst []byte, start int) []byte {
+	n := 0
+	for _, b := range dst[start:] {
+		if c.needEscape(b) {
+			n++
+		}
+	}
+
+	if n == 0 {
+		return dst
+	}
+
+	end := len(dst)
+	dst = append(dst, make([]byte, n)...)
+
+	for i, j := end-1, len(dst)-1; i >= start; i-- {
+		dst[j] = dst[i]
+		j--
+		if c.needEscape(dst[i]) {
+			dst[j] = c.escape
+			j--
+		}
+	}
+
+	return dst
+}
+
+func (c *TextCodec) needEscape(b byte) bool {
+	return b == c.separator || b == c.keyValueSeparator || b == c.escape
+}
+
+// split splits data by unescaped sep, escapes are kept in parts.
+// empty data splits to no parts.
+func (c *TextCodec) split(data []byte, sep byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	parts := make([][]byte, 0)
+	start := 0
+
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case c.escape:
+			i++
+		case sep:
+			parts = append(parts, data[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, data[start:])
+}
+
+func (c *TextCodec) unescape(data []byte) []byte {
+	if bytes.IndexByte(data, c.escape) == -1 {
+		return data
+	}
+
+	d := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		if data[i] == c.escape && i+1 < len(data) {
+			i++
+		}
+		d = append(d, data[i])
+	}
+
+	return d
+}
@@ -0,0 +1,343 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+)
+
+// TagLogfmt is the struct tag for logfmt keys.
+//
+//	Latency time.Duration `logfmt:"latency,omitempty"`
+const TagLogfmt = "logfmt"
+
+// MarshalLogfmt encodes struct or map v as logfmt line, without line ending.
+//
+//	level=info msg="request done" latency=1.5ms req.path=/api
+//
+// Fields are in order of struct, nested structs are flattened as `a.b` keys, and keys of maps are sorted.
+// Values are converted by MarshalText, and quoted when containing spaces, '=', '"' or control characters.
+func MarshalLogfmt(v any) ([]byte, error) {
+	return appendLogfmtValue(nil, v)
+}
+
+// AppendLogfmt appends key/value pairs to dst as logfmt, keys and values are converted by MarshalText.
+// values of error are converted by Error().
+func AppendLogfmt(dst []byte, keyvals ...any) ([]byte, error) {
+	if len(keyvals)%2 != 0 {
+		return nil, pkgerrors.Errorf("logfmt need key/value pairs, but got %d values", len(keyvals))
+	}
+
+	for i := 0; i < len(keyvals); i += 2 {
+		key, err := MarshalText(keyvals[i])
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "logfmt key %d", i/2)
+		}
+
+		var value []byte
+		if e, ok := keyvals[i+1].(error); ok {
+			value = []byte(e.Error())
+		} else if value, err = MarshalText(keyvals[i+1]); err != nil {
+			return nil, pkgerrors.Wrapf(err, "logfmt %s", key)
+		}
+
+		if dst, err = appendLogfmtPair(dst, string(key), value); err != nil {
+			return nil, err
+		}
+	}
+
+	return dst, nil
+}
+
+// UnmarshalLogfmt decodes logfmt line into struct or map v.
+// Values are converted by UnmarshalText, unknown keys are ignored, and empty values are left as zero values.
+func UnmarshalLogfmt(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("unmarshal logfmt need non-nil ptr value, but got %T", v)
+	}
+
+	pairs := map[string]string{}
+	if err := EachLogfmtPair(data, func(key string, value string) error {
+		pairs[key] = value
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		return unmarshalLogfmtMap(pairs, rv)
+	case reflect.Struct:
+		columns, err := structColumnsOf(rv.Type(), TagLogfmt, "", nil)
+		if err != nil {
+			return pkgerrors.Wrap(err, "unmarshal logfmt")
+		}
+
+		for _, c := range columns {
+			value, ok := pairs[c.name]
+			if !ok || value == "" {
+				continue
+			}
+			fv, _ := c.value(rv, true)
+			if err := c.tc.UnmarshalText(fv, []byte(value)); err != nil {
+				return pkgerrors.Wrapf(err, "unmarshal logfmt %s", c.name)
+			}
+		}
+		return nil
+	}
+
+	return pkgerrors.Errorf("unmarshal logfmt need struct or map value, but got %T", v)
+}
+
+// EachLogfmtPair parses logfmt line, and calls each with key/value pairs in order.
+// Value of bare key is empty.
+func EachLogfmtPair(data []byte, each func(key string, value string) error) error {
+	s := string(data)
+
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return nil
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return r == '=' || r == '"' || unicode.IsSpace(r)
+		})
+		if end < 0 {
+			end = len(s)
+		}
+
+		key := s[:end]
+		if key == "" {
+			return pkgerrors.Errorf("unexpected %q, missing key", s[0])
+		}
+		s = s[end:]
+
+		value := ""
+
+		if strings.HasPrefix(s, `"`) {
+			return pkgerrors.Errorf("unexpected '\"' in key %s", key)
+		}
+
+		if strings.HasPrefix(s, "=") {
+			s = s[1:]
+
+			if strings.HasPrefix(s, `"`) {
+				q := closingQuote(s[1:], '"')
+				if q < 0 {
+					return pkgerrors.Errorf("unterminated quoted value of %s", key)
+				}
+				q += 2
+
+				v, err := strconv.Unquote(s[:q])
+				if err != nil {
+					return pkgerrors.Errorf("invalid quoted value of %s: %s", key, err)
+				}
+				value = v
+				s = s[q:]
+
+				if s != "" && !unicode.IsSpace(rune(s[0])) {
+					return pkgerrors.Errorf("unexpected %q after quoted value of %s", s[0], key)
+				}
+			} else {
+				end := strings.IndexFunc(s, unicode.IsSpace)
+				if end < 0 {
+					end = len(s)
+				}
+				value = s[:end]
+				s = s[end:]
+
+				if strings.ContainsAny(value, `="`) {
+					return pkgerrors.Errorf("unexpected %q in value of %s, which should be quoted", value, key)
+				}
+			}
+		}
+
+		if err := each(key, value); err != nil {
+			return err
+		}
+	}
+}
+
+// LogfmtEncoder writes logfmt lines
+type LogfmtEncoder struct {
+	w io.Writer
+}
+
+func NewLogfmtEncoder(w io.Writer) *LogfmtEncoder {
+	return &LogfmtEncoder{w: w}
+}
+
+// Encode writes struct or map v as a line, like MarshalLogfmt
+func (e *LogfmtEncoder) Encode(v any) error {
+	line, err := appendLogfmtValue(nil, v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// EncodeKeyvals writes key/value pairs as a line, like AppendLogfmt
+func (e *LogfmtEncoder) EncodeKeyvals(keyvals ...any) error {
+	line, err := AppendLogfmt(nil, keyvals...)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// LogfmtDecoder reads logfmt lines
+type LogfmtDecoder struct {
+	l *lineReader
+}
+
+func NewLogfmtDecoder(r io.Reader) *LogfmtDecoder {
+	return &LogfmtDecoder{l: newLineReader(r)}
+}
+
+// Decode decodes next non-blank line into struct or map v, like UnmarshalLogfmt.
+// io.EOF is returned when no more lines, and errors are *SyntaxError with line number.
+func (d *LogfmtDecoder) Decode(v any) error {
+	for {
+		line, err := d.l.readLine()
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := UnmarshalLogfmt([]byte(line), v); err != nil {
+			return &SyntaxError{Line: d.l.line, Err: err}
+		}
+		return nil
+	}
+}
+
+func appendLogfmtValue(dst []byte, v any) ([]byte, error) {
+	rv := reflectx.Indirect(reflect.ValueOf(v))
+
+	switch rv.Kind() {
+	case reflect.Map:
+		return appendLogfmtMap(dst, rv)
+	case reflect.Struct:
+		columns, err := structColumnsOf(rv.Type(), TagLogfmt, "", nil)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "marshal logfmt")
+		}
+
+		for _, c := range columns {
+			fv, ok := c.value(rv, false)
+			if !ok || c.tc.skipNull(fv) || (c.omitempty && reflectx.IsEmptyValue(fv)) {
+				continue
+			}
+
+			text, err := c.tc.MarshalText(fv)
+			if err != nil {
+				return nil, pkgerrors.Wrapf(err, "marshal logfmt %s", c.name)
+			}
+
+			if dst, err = appendLogfmtPair(dst, c.name, text); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+
+	if !rv.IsValid() {
+		return dst, nil
+	}
+	return nil, pkgerrors.Errorf("marshal logfmt need struct or map value, but got %T", v)
+}
+
+func appendLogfmtMap(dst []byte, rv reflect.Value) ([]byte, error) {
+	type pair struct {
+		key   string
+		value []byte
+	}
+
+	pairs := make([]pair, 0, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := MarshalText(iter.Key())
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "marshal logfmt")
+		}
+		v, err := MarshalText(iter.Value())
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "marshal logfmt %s", k)
+		}
+		pairs = append(pairs, pair{key: string(k), value: v})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].key < pairs[j].key
+	})
+
+	var err error
+	for _, p := range pairs {
+		if dst, err = appendLogfmtPair(dst, p.key, p.value); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func unmarshalLogfmtMap(pairs map[string]string, rv reflect.Value) error {
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(pairs)))
+	}
+
+	for key, value := range pairs {
+		k := reflect.New(rv.Type().Key()).Elem()
+		if err := UnmarshalText(k, []byte(key)); err != nil {
+			return pkgerrors.Wrap(err, "unmarshal logfmt")
+		}
+		v := reflect.New(rv.Type().Elem()).Elem()
+		if err := UnmarshalText(v, []byte(value)); err != nil {
+			return pkgerrors.Wrapf(err, "unmarshal logfmt %s", key)
+		}
+		rv.SetMapIndex(k, v)
+	}
+	return nil
+}
+
+func appendLogfmtPair(dst []byte, key string, value []byte) ([]byte, error) {
+	if key == "" || strings.IndexFunc(key, isLogfmtSpecial) >= 0 || !utf8.ValidString(key) {
+		return nil, pkgerrors.Errorf("invalid logfmt key %q", key)
+	}
+
+	if len(dst) > 0 {
+		dst = append(dst, ' ')
+	}
+	dst = append(dst, key...)
+	dst = append(dst, '=')
+
+	if bytes.IndexFunc(value, isLogfmtSpecial) >= 0 || !utf8.Valid(value) {
+		return strconv.AppendQuote(dst, string(value)), nil
+	}
+	return append(dst, value...), nil
+}
+
+// isLogfmtSpecial reports whether r could not be kept as-is in logfmt keys or values
+func isLogfmtSpecial(r rune) bool {
+	return r == '=' || r == '"' || r == '\\' || unicode.IsSpace(r) || unicode.IsControl(r)
+}
@@ -0,0 +1,122 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type LogfmtRequest struct {
+	Method string `logfmt:"method"`
+	Path   string `logfmt:"path"`
+}
+
+type LogfmtEntry struct {
+	Level   string         `logfmt:"level"`
+	Msg     string         `logfmt:"msg"`
+	Latency time.Duration  `logfmt:"latency,omitempty"`
+	Status  int            `logfmt:"status"`
+	Request *LogfmtRequest `logfmt:"req"`
+}
+
+func TestMarshalLogfmt(t *testing.T) {
+	data, err := MarshalLogfmt(LogfmtEntry{
+		Level:   "info",
+		Msg:     "request \"done\"\n",
+		Status:  200,
+		Request: &LogfmtRequest{Method: "GET", Path: "/a=b"},
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`level=info msg="request \"done\"\n" status=200 req.method=GET req.path="/a=b"`))
+
+	data, err = MarshalLogfmt(&LogfmtEntry{Level: "warn", Latency: time.Millisecond})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`level=warn msg= latency=1ms status=0`))
+
+	data, err = MarshalLogfmt(map[string]int{"b": 2, "a": 1})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`a=1 b=2`))
+
+	_, err = MarshalLogfmt(map[string]string{"a b": "c"})
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestAppendLogfmt(t *testing.T) {
+	data, err := AppendLogfmt([]byte("ts=1"), "msg", "hello world", "err", errors.New("failed"), "n", 1.5)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`ts=1 msg="hello world" err=failed n=1.5`))
+
+	_, err = AppendLogfmt(nil, "msg")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestUnmarshalLogfmt(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		e := LogfmtEntry{}
+		err := UnmarshalLogfmt([]byte(`level=info  msg="request \"done\"\n" latency=1ms status= req.path=/api unknown debug`), &e)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(e).To(Equal(LogfmtEntry{
+			Level:   "info",
+			Msg:     "request \"done\"\n",
+			Latency: time.Millisecond,
+			Request: &LogfmtRequest{Path: "/api"},
+		}))
+	})
+
+	t.Run("map", func(t *testing.T) {
+		m := map[string]string{}
+		err := UnmarshalLogfmt([]byte(`a=1 b="x y" flag`), &m)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(m).To(Equal(map[string]string{"a": "1", "b": "x y", "flag": ""}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, line := range []string{
+			`=1`,
+			`a="x`,
+			`a="x"b`,
+			`a=x"y`,
+			`a=b=c`,
+			`a"b=1`,
+		} {
+			m := map[string]string{}
+			NewWithT(t).Expect(UnmarshalLogfmt([]byte(line), &m)).NotTo(BeNil(), line)
+		}
+
+		NewWithT(t).Expect(UnmarshalLogfmt([]byte(`status=ok`), &LogfmtEntry{})).NotTo(BeNil())
+	})
+}
+
+func TestLogfmtEncoderDecoder(t *testing.T) {
+	entries := []LogfmtEntry{
+		{Level: "info", Msg: "start", Status: 1},
+		{Level: "error", Msg: "tab\there", Latency: time.Second, Status: 500, Request: &LogfmtRequest{Method: "POST", Path: "/"}},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	e := NewLogfmtEncoder(buf)
+	for _, entry := range entries {
+		NewWithT(t).Expect(e.Encode(entry)).To(BeNil())
+	}
+	NewWithT(t).Expect(e.EncodeKeyvals("level", "debug", "status", "x")).To(BeNil())
+
+	d := NewLogfmtDecoder(strings.NewReader(buf.String()))
+
+	for _, entry := range entries {
+		decoded := LogfmtEntry{}
+		NewWithT(t).Expect(d.Decode(&decoded)).To(BeNil())
+		NewWithT(t).Expect(decoded).To(Equal(entry))
+	}
+
+	err := d.Decode(&LogfmtEntry{})
+	syntaxErr := &SyntaxError{}
+	NewWithT(t).Expect(errors.As(err, &syntaxErr)).To(BeTrue())
+	NewWithT(t).Expect(syntaxErr.Line).To(Equal(3))
+
+	NewWithT(t).Expect(d.Decode(&LogfmtEntry{})).To(Equal(io.EOF))
+}
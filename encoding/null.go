@@ -0,0 +1,48 @@
+package encoding
+
+import (
+	"bytes"
+)
+
+// NullPolicy is how nil values, like nil pointers, are converted
+type NullPolicy int
+
+const (
+	// NullAsEmpty encodes nil as empty text, and decoding always allocates pointers
+	NullAsEmpty NullPolicy = iota
+	// NullAsText encodes nil as the null text, which decodes back to nil pointer
+	NullAsText
+	// NullSkipped skips nil elements of lists and maps, and nil fields of struct layers like env
+	NullSkipped
+)
+
+// DefaultNullText is the null text of NullAsText unless WithNullText
+const DefaultNullText = "null"
+
+// IsNullText reports whether data is the null text of DefaultTextCodec
+func IsNullText(data []byte) bool {
+	return DefaultTextCodec.IsNullText(data)
+}
+
+// NullPolicy returns NullPolicy of c
+func (c *TextCodec) NullPolicy() NullPolicy {
+	return c.nullPolicy
+}
+
+// IsNullText reports whether data is the null text, always false unless NullAsText
+func (c *TextCodec) IsNullText(data []byte) bool {
+	return c.nullPolicy == NullAsText && bytes.Equal(data, c.nullText)
+}
+
+// appendNull appends null text to dst for nil value
+func (c *TextCodec) appendNull(dst []byte) []byte {
+	if c.nullPolicy == NullAsText {
+		return append(dst, c.nullText...)
+	}
+	return dst
+}
+
+// skipNull reports whether nil v should be skipped
+func (c *TextCodec) skipNull(v any) bool {
+	return c.nullPolicy == NullSkipped && isNil(v)
+}
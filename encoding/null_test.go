@@ -0,0 +1,90 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+func TestTextCodecNullPolicy(t *testing.T) {
+	t.Run("empty by default", func(t *testing.T) {
+		text, err := MarshalText((*string)(nil))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(text).To(BeNil())
+
+		var s *string
+		NewWithT(t).Expect(UnmarshalText(&s, []byte("null"))).To(BeNil())
+		NewWithT(t).Expect(s).To(Equal(ptr.String("null")))
+
+		NewWithT(t).Expect(IsNullText([]byte("null"))).To(BeFalse())
+	})
+
+	t.Run("null text", func(t *testing.T) {
+		c := NewTextCodec(WithNullText("~"))
+
+		NewWithT(t).Expect(c.IsNullText([]byte("~"))).To(BeTrue())
+		NewWithT(t).Expect(c.IsNullText([]byte(""))).To(BeFalse())
+
+		text, err := c.MarshalText((*string)(nil))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("~"))
+
+		text, err = c.MarshalText(nil)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("~"))
+
+		text, err = c.MarshalText([]*int{ptr.Int(1), nil, ptr.Int(3)})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1,~,3"))
+
+		s := ptr.String("x")
+		NewWithT(t).Expect(c.UnmarshalText(&s, []byte("~"))).To(BeNil())
+		NewWithT(t).Expect(s).To(BeNil())
+
+		NewWithT(t).Expect(c.UnmarshalText(&s, []byte(""))).To(BeNil())
+		NewWithT(t).Expect(s).To(Equal(ptr.String("")))
+
+		var list []*int
+		NewWithT(t).Expect(c.UnmarshalText(&list, []byte("1,~,3"))).To(BeNil())
+		NewWithT(t).Expect(list).To(Equal([]*int{ptr.Int(1), nil, ptr.Int(3)}))
+
+		// null text of non-pointer is decoded as it is
+		var str string
+		NewWithT(t).Expect(c.UnmarshalText(&str, []byte("~"))).To(BeNil())
+		NewWithT(t).Expect(str).To(Equal("~"))
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		c := NewTextCodec(WithNullPolicy(NullSkipped))
+
+		text, err := c.MarshalText([]*int{nil, ptr.Int(1), nil, ptr.Int(3)})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1,3"))
+
+		text, err = c.MarshalText(map[string]*int{"a": ptr.Int(1), "b": nil})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("a=1"))
+	})
+
+	t.Run("layers", func(t *testing.T) {
+		type V struct {
+			Name *string `name:"name" env:"NAME"`
+			Port int     `name:"port" env:"PORT"`
+		}
+
+		c := NewTextCodec(WithNullText("null"))
+
+		values, err := FormCodec{Tag: "name", Text: c}.Marshal(V{})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(values.Get("name")).To(Equal("null"))
+
+		v := V{Name: ptr.String("x")}
+		NewWithT(t).Expect(FormCodec{Tag: "name", Text: c}.Unmarshal(values, &v)).To(BeNil())
+		NewWithT(t).Expect(v.Name).To(BeNil())
+
+		data, err := MarshalEnv(V{}, WithEnvTextCodec(NewTextCodec(WithNullPolicy(NullSkipped))))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(data)).To(Equal("PORT=0\n"))
+	})
+}
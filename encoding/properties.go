@@ -0,0 +1,291 @@
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// PropertiesReader reads key/value pairs from Java .properties file.
+//
+//	# comment
+//	! comment
+//	db.host = localhost
+//	db.port: 5432
+//	greeting hello 世界
+//	list = a,\
+//	       b
+type PropertiesReader struct {
+	l *lineReader
+}
+
+func NewPropertiesReader(r io.Reader) *PropertiesReader {
+	return &PropertiesReader{l: newLineReader(r)}
+}
+
+// Read returns next key/value pair, io.EOF when no more pairs
+func (r *PropertiesReader) Read() (key string, value string, err error) {
+	for {
+		c, err := parsePropertiesChunk(r.l)
+		if err != nil {
+			return "", "", err
+		}
+		if c.entry {
+			return c.key, c.value, nil
+		}
+	}
+}
+
+// ReadPropertiesFile reads .properties file as KeyValueFile, which keeps comments and order of keys
+func ReadPropertiesFile(r io.Reader) (*KeyValueFile, error) {
+	return readKeyValueFile(r, parsePropertiesChunk, formatProperty)
+}
+
+// UnmarshalProperties fills struct v from .properties file, dotted keys are mapped like Unflatten
+func UnmarshalProperties(r io.Reader, v any, tag string) error {
+	f, err := ReadPropertiesFile(r)
+	if err != nil {
+		return err
+	}
+	return Unflatten(f.Map(), v, tag)
+}
+
+// MarshalProperties writes struct v as .properties file, keys are flattened like Flatten and sorted
+func MarshalProperties(w io.Writer, v any, tag string) error {
+	m, err := Flatten(v, tag)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pw := NewPropertiesWriter(w)
+	for _, k := range keys {
+		if err := pw.Write(k, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PropertiesWriter writes key/value pairs as .properties file
+type PropertiesWriter struct {
+	w io.Writer
+}
+
+func NewPropertiesWriter(w io.Writer) *PropertiesWriter {
+	return &PropertiesWriter{w: w}
+}
+
+// WriteComment writes each line of comment with '#'
+func (w *PropertiesWriter) WriteComment(comment string) error {
+	return writeComment(w.w, "# ", comment)
+}
+
+// Write writes key=value line with escapes
+func (w *PropertiesWriter) Write(key string, value string) error {
+	_, err := io.WriteString(w.w, formatProperty(key, value)+"\n")
+	return err
+}
+
+func formatProperty(key string, value string) string {
+	return escapeProperty(key, true) + "=" + escapeProperty(value, false)
+}
+
+func escapeProperty(s string, isKey bool) string {
+	b := &strings.Builder{}
+
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(b, `\u%04x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+const propertiesWhitespace = " \t\f"
+
+func parsePropertiesChunk(l *lineReader) (*kvChunk, error) {
+	line, err := l.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	text, eol := cutEOL(line)
+	logical := strings.TrimLeft(text, propertiesWhitespace)
+
+	if logical == "" || logical[0] == '#' || logical[0] == '!' {
+		return &kvChunk{raw: line, eol: eol}, nil
+	}
+
+	prefix := text[:len(text)-len(logical)]
+
+	// odd trailing '\' continues next line, leading whitespace of which is skipped
+	for continuesLine(logical) {
+		logical = logical[:len(logical)-1]
+
+		next, err := l.readLine()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		line += next
+		next, eol = cutEOL(next)
+		logical += strings.TrimLeft(next, propertiesWhitespace)
+	}
+
+	key, value := splitProperty(logical)
+
+	key, err = unescapeProperty(key)
+	if err != nil {
+		return nil, l.errorf("invalid key %q: %s", key, err)
+	}
+
+	value, err = unescapeProperty(value)
+	if err != nil {
+		return nil, l.errorf("invalid value of %s: %s", key, err)
+	}
+
+	return &kvChunk{
+		raw:    line,
+		entry:  true,
+		key:    key,
+		value:  value,
+		prefix: prefix,
+		eol:    eol,
+	}, nil
+}
+
+func continuesLine(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits s by first unescaped '=', ':' or whitespace, escapes are kept
+func splitProperty(s string) (string, string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '=' || c == ':':
+			return s[:i], strings.TrimLeft(s[i+1:], propertiesWhitespace)
+		case strings.IndexByte(propertiesWhitespace, c) >= 0:
+			rest := strings.TrimLeft(s[i:], propertiesWhitespace)
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], propertiesWhitespace)
+			}
+			return s[:i], rest
+		}
+	}
+	return s, ""
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	b := &strings.Builder{}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if i >= len(s) {
+			break
+		}
+
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, n, err := unescapeUnicode(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+			i += n
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// unescapeUnicode decodes XXXX after `\u`, with the low surrogate `\uXXXX` followed.
+// returns rune and length of s consumed
+func unescapeUnicode(s string) (rune, int, error) {
+	hex4 := func(s string) (rune, error) {
+		if len(s) < 4 {
+			return 0, pkgerrors.Errorf("malformed \\u escape %q", s)
+		}
+		u, err := strconv.ParseUint(s[:4], 16, 16)
+		if err != nil {
+			return 0, pkgerrors.Errorf("malformed \\u escape %q", s[:4])
+		}
+		return rune(u), nil
+	}
+
+	r, err := hex4(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if utf16.IsSurrogate(r) && len(s) >= 10 && s[4:6] == `\u` {
+		if r2, err := hex4(s[6:]); err == nil {
+			if d := utf16.DecodeRune(r, r2); d != '�' {
+				return d, 10, nil
+			}
+		}
+	}
+
+	return r, 4, nil
+}
@@ -0,0 +1,135 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const propertiesFile = `# database
+! legacy comment
+db.host = localhost
+db.port:5432
+  greeting hello \u4e16\u754c
+emoji=\ud83d\ude00
+key\ with\ spaces=v
+list = a,\
+       b,\\
+path=c:\\dir
+`
+
+func TestPropertiesReader(t *testing.T) {
+	r := NewPropertiesReader(strings.NewReader(propertiesFile))
+
+	pairs := make([][2]string, 0)
+	for {
+		k, v, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		NewWithT(t).Expect(err).To(BeNil())
+		pairs = append(pairs, [2]string{k, v})
+	}
+
+	NewWithT(t).Expect(pairs).To(Equal([][2]string{
+		{"db.host", "localhost"},
+		{"db.port", "5432"},
+		{"greeting", "hello 世界"},
+		{"emoji", "😀"},
+		{"key with spaces", "v"},
+		{"list", `a,b,\`},
+		{"path", `c:\dir`},
+	}))
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := ReadPropertiesFile(strings.NewReader("a=1\n\nb=\\u12\n"))
+
+		syntaxErr := &SyntaxError{}
+		NewWithT(t).Expect(errors.As(err, &syntaxErr)).To(BeTrue())
+		NewWithT(t).Expect(syntaxErr.Line).To(Equal(3))
+	})
+}
+
+func TestPropertiesFile(t *testing.T) {
+	f, err := ReadPropertiesFile(strings.NewReader(propertiesFile))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	f.Set("greeting", " hi: there")
+	f.Set("new key", "#1")
+
+	buf := bytes.NewBuffer(nil)
+	_, err = f.WriteTo(buf)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(buf.String()).To(Equal(`# database
+! legacy comment
+db.host = localhost
+db.port:5432
+  greeting=\ hi\: there
+emoji=\ud83d\ude00
+key\ with\ spaces=v
+list = a,\
+       b,\\
+path=c:\\dir
+new\ key=\#1
+`))
+
+	f2, err := ReadPropertiesFile(bytes.NewReader(buf.Bytes()))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	greeting, _ := f2.Get("greeting")
+	NewWithT(t).Expect(greeting).To(Equal(" hi: there"))
+	newKey, _ := f2.Get("new key")
+	NewWithT(t).Expect(newKey).To(Equal("#1"))
+}
+
+func TestPropertiesWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := NewPropertiesWriter(buf)
+
+	NewWithT(t).Expect(w.WriteComment("generated")).To(BeNil())
+	NewWithT(t).Expect(w.Write("a", "x=1\ty\x01世界")).To(BeNil())
+
+	NewWithT(t).Expect(buf.String()).To(Equal("# generated\na=x\\=1\\ty\\u0001世界\n"))
+
+	f, err := ReadPropertiesFile(bytes.NewReader(buf.Bytes()))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(f.Map()).To(Equal(map[string]string{"a": "x=1\ty\x01世界"}))
+}
+
+type PropertiesDB struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type PropertiesConfig struct {
+	Greeting string            `json:"greeting"`
+	DB       PropertiesDB      `json:"db"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func TestMarshalProperties(t *testing.T) {
+	c := PropertiesConfig{}
+	NewWithT(t).Expect(UnmarshalProperties(strings.NewReader(propertiesFile), &c, "json")).To(BeNil())
+	NewWithT(t).Expect(c).To(Equal(PropertiesConfig{
+		Greeting: "hello 世界",
+		DB:       PropertiesDB{Host: "localhost", Port: 5432},
+	}))
+
+	c.Labels = map[string]string{"app.name": "srv"}
+
+	buf := bytes.NewBuffer(nil)
+	NewWithT(t).Expect(MarshalProperties(buf, c, "json")).To(BeNil())
+	NewWithT(t).Expect(buf.String()).To(Equal(`db.host=localhost
+db.port=5432
+greeting=hello 世界
+labels.app.name=srv
+`))
+
+	c2 := PropertiesConfig{}
+	NewWithT(t).Expect(UnmarshalProperties(bytes.NewReader(buf.Bytes()), &c2, "json")).To(BeNil())
+	NewWithT(t).Expect(c2).To(Equal(c))
+}
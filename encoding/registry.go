@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// TextCodecRegistry holds text codecs for types which could not implement encoding.TextMarshaler,
+// like types from third-party libraries.
+type TextCodecRegistry struct {
+	codecs  sync.Map
+	parsers sync.Map
+	// generation is increased on each register, which drops cached TypeCodec of TextCodec
+	generation atomic.Uint64
+	// basic is set when codec of basic type registered, which disables fast path of basic types of TextCodec
+	basic atomic.Bool
+}
+
+func NewTextCodecRegistry() *TextCodecRegistry {
+	return &TextCodecRegistry{}
+}
+
+// DefaultTextCodecRegistry is the registry used by TextCodec unless WithRegistry
+var DefaultTextCodecRegistry = NewTextCodecRegistry()
+
+type registeredTextCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(v any, data []byte) error
+}
+
+// RegisterTextCodec registers text codec of T into DefaultTextCodecRegistry
+func RegisterTextCodec[T any](marshal func(T) ([]byte, error), unmarshal func(*T, []byte) error) {
+	RegisterTextCodecTo[T](DefaultTextCodecRegistry, marshal, unmarshal)
+}
+
+// RegisterTextCodecTo registers text codec of T into r.
+// Registered codec is checked before any other conversion.
+func RegisterTextCodecTo[T any](r *TextCodecRegistry, marshal func(T) ([]byte, error), unmarshal func(*T, []byte) error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.PkgPath() == "" && typ.Name() != "" {
+		r.basic.Store(true)
+	}
+	r.codecs.Store(typ, &registeredTextCodec{
+		marshal: func(v any) ([]byte, error) {
+			return marshal(v.(T))
+		},
+		unmarshal: func(v any, data []byte) error {
+			return unmarshal(v.(*T), data)
+		},
+	})
+	r.generation.Add(1)
+}
+
+// RegisterParseFunc registers parse func of fmt.Stringer T into DefaultTextCodecRegistry
+func RegisterParseFunc[T fmt.Stringer](parse func(s string) (T, error)) {
+	RegisterParseFuncTo[T](DefaultTextCodecRegistry, parse)
+}
+
+// RegisterParseFuncTo registers parse func of fmt.Stringer T into r.
+// T will be marshaled by String() and unmarshaled by parse,
+// when T is not encoding.TextMarshaler or encoding.BinaryMarshaler.
+func RegisterParseFuncTo[T fmt.Stringer](r *TextCodecRegistry, parse func(s string) (T, error)) {
+	r.parsers.Store(reflect.TypeOf((*T)(nil)).Elem(), &registeredTextCodec{
+		marshal: func(v any) ([]byte, error) {
+			return []byte(v.(T).String()), nil
+		},
+		unmarshal: func(v any, data []byte) error {
+			x, err := parse(string(data))
+			if err != nil {
+				return err
+			}
+			*(v.(*T)) = x
+			return nil
+		},
+	})
+	r.generation.Add(1)
+}
+
+func (r *TextCodecRegistry) codec(t reflect.Type) (*registeredTextCodec, bool) {
+	if v, ok := r.codecs.Load(t); ok {
+		return v.(*registeredTextCodec), true
+	}
+	return nil, false
+}
+
+func (r *TextCodecRegistry) parser(t reflect.Type) (*registeredTextCodec, bool) {
+	if v, ok := r.parsers.Load(t); ok {
+		return v.(*registeredTextCodec), true
+	}
+	return nil, false
+}
+
+func (r *TextCodecRegistry) has(t reflect.Type) bool {
+	if _, ok := r.codecs.Load(t); ok {
+		return true
+	}
+	_, ok := r.parsers.Load(t)
+	return ok
+}
@@ -0,0 +1,190 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+// UUID as third-party type without TextMarshaler
+type UUID [4]byte
+
+func marshalUUID(u UUID) ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func unmarshalUUID(u *UUID, data []byte) error {
+	_, err := hex.Decode(u[:], data)
+	return err
+}
+
+// Decimal as third-party type
+type Decimal struct {
+	Int, Exp int
+}
+
+func init() {
+	RegisterTextCodec(func(d Decimal) ([]byte, error) {
+		return []byte(fmt.Sprintf("%de%d", d.Int, d.Exp)), nil
+	}, func(d *Decimal, data []byte) error {
+		i, exp, ok := strings.Cut(string(data), "e")
+		if !ok {
+			return errors.New("invalid decimal")
+		}
+		d.Int, _ = strconv.Atoi(i)
+		d.Exp, _ = strconv.Atoi(exp)
+		return nil
+	})
+}
+
+type Level int
+
+func (l Level) String() string {
+	switch l {
+	case 1:
+		return "debug"
+	case 2:
+		return "info"
+	}
+	return "unknown"
+}
+
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case "debug":
+		return 1, nil
+	case "info":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+// Point as encoding.BinaryMarshaler only
+type Point struct {
+	X, Y uint8
+}
+
+func (p Point) MarshalBinary() ([]byte, error) {
+	return []byte{p.X, p.Y}, nil
+}
+
+func (p *Point) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("invalid point")
+	}
+	p.X, p.Y = data[0], data[1]
+	return nil
+}
+
+func TestTextCodecRegistry(t *testing.T) {
+	t.Run("global", func(t *testing.T) {
+		v := struct {
+			Decimal    Decimal
+			PtrDecimal **Decimal
+			Decimals   []Decimal
+		}{}
+		rv := reflect.ValueOf(&v).Elem()
+
+		NewWithT(t).Expect(UnmarshalText(rv.FieldByName("Decimal"), []byte("15e-1"))).To(BeNil())
+		NewWithT(t).Expect(v.Decimal).To(Equal(Decimal{15, -1}))
+
+		NewWithT(t).Expect(UnmarshalText(rv.FieldByName("PtrDecimal"), []byte("1e2"))).To(BeNil())
+		NewWithT(t).Expect(**v.PtrDecimal).To(Equal(Decimal{1, 2}))
+
+		NewWithT(t).Expect(UnmarshalText(rv.FieldByName("Decimals"), []byte("1e0,2e1"))).To(BeNil())
+		NewWithT(t).Expect(v.Decimals).To(Equal([]Decimal{{1, 0}, {2, 1}}))
+
+		text, err := MarshalText(rv.FieldByName("Decimals"))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1e0,2e1"))
+
+		text, err = MarshalText(v.PtrDecimal)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1e2"))
+
+		err = UnmarshalText(&v.Decimal, []byte("x"))
+		e := &UnmarshalTextError{}
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Type).To(Equal(reflect.TypeOf(Decimal{})))
+	})
+
+	t.Run("scoped", func(t *testing.T) {
+		r := NewTextCodecRegistry()
+		RegisterTextCodecTo(r, marshalUUID, unmarshalUUID)
+
+		c := NewTextCodec(WithRegistry(r))
+
+		u := UUID{}
+		NewWithT(t).Expect(c.UnmarshalText(&u, []byte("0a0b0c0d"))).To(BeNil())
+		NewWithT(t).Expect(u).To(Equal(UUID{10, 11, 12, 13}))
+
+		text, err := c.MarshalText(u)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("0a0b0c0d"))
+
+		text, err = MarshalText(u)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("10,11,12,13"))
+	})
+
+	t.Run("basic type", func(t *testing.T) {
+		r := NewTextCodecRegistry()
+		RegisterTextCodecTo(r, func(i int) ([]byte, error) {
+			return []byte(strconv.FormatInt(int64(i), 16)), nil
+		}, func(i *int, data []byte) error {
+			x, err := strconv.ParseInt(string(data), 16, 0)
+			*i = int(x)
+			return err
+		})
+
+		c := NewTextCodec(WithRegistry(r))
+
+		text, err := c.MarshalText(255)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("ff"))
+
+		i := 0
+		NewWithT(t).Expect(c.UnmarshalText(&i, []byte("10"))).To(BeNil())
+		NewWithT(t).Expect(i).To(Equal(16))
+	})
+
+	t.Run("fmt.Stringer with parse func", func(t *testing.T) {
+		r := NewTextCodecRegistry()
+		RegisterParseFuncTo(r, ParseLevel)
+
+		c := NewTextCodec(WithRegistry(r))
+
+		levels := make([]Level, 0)
+		NewWithT(t).Expect(c.UnmarshalText(&levels, []byte("info,debug"))).To(BeNil())
+		NewWithT(t).Expect(levels).To(Equal([]Level{2, 1}))
+
+		text, err := c.MarshalText(levels)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("info,debug"))
+
+		NewWithT(t).Expect(c.UnmarshalText(ptr.Ptr(Level(0)), []byte("trace"))).NotTo(BeNil())
+
+		text, err = MarshalText(Level(2))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("2"))
+	})
+
+	t.Run("encoding.BinaryMarshaler", func(t *testing.T) {
+		p := Point{}
+		NewWithT(t).Expect(UnmarshalText(&p, []byte("AQI="))).To(BeNil())
+		NewWithT(t).Expect(p).To(Equal(Point{1, 2}))
+
+		text, err := NewTextCodec(WithBytesEncoding(BytesHex)).MarshalText(&p)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("0102"))
+
+		NewWithT(t).Expect(UnmarshalText(&p, []byte("AQ=="))).NotTo(BeNil())
+	})
+}
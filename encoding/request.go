@@ -0,0 +1,165 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/utilsgo/x/types"
+)
+
+const (
+	// TagIn is the struct tag for location of request parameter, one of query, path, header or cookie
+	TagIn = "in"
+	// TagName is the struct tag for name of request parameter
+	TagName = "name"
+)
+
+type requestOptions struct {
+	pathParam func(r *http.Request, name string) (string, bool)
+	text      *TextCodec
+}
+
+type RequestOption func(o *requestOptions)
+
+// WithPathParam sets getter of path parameters, which depends on the router.
+func WithPathParam(get func(r *http.Request, name string) (string, bool)) RequestOption {
+	return func(o *requestOptions) {
+		o.pathParam = get
+	}
+}
+
+// WithRequestTextCodec sets TextCodec to convert parameter values. default DefaultTextCodec
+func WithRequestTextCodec(c *TextCodec) RequestOption {
+	return func(o *requestOptions) {
+		o.text = c
+	}
+}
+
+// RequestParameterError describes parameter which could not be bound
+type RequestParameterError struct {
+	In   string
+	Name string
+	Err  error
+}
+
+func (e *RequestParameterError) Error() string {
+	return fmt.Sprintf("%s parameter %s: %s", e.In, e.Name, e.Err)
+}
+
+func (e *RequestParameterError) Unwrap() error {
+	return e.Err
+}
+
+// RequestError aggregates all parameters which could not be bound
+type RequestError struct {
+	Parameters []*RequestParameterError
+}
+
+func (e *RequestError) Error() string {
+	buf := bytes.NewBufferString("bind request failed: ")
+	for i, p := range e.Parameters {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(p.Error())
+	}
+	return buf.String()
+}
+
+// BindRequest fills struct v from parameters of r, located by the `in` tag and named by the `name` tag.
+// Fields without `in` tag are skipped.
+// Conversion errors of all parameters are returned together as *RequestError.
+func BindRequest(r *http.Request, v any, opts ...RequestOption) error {
+	o := &requestOptions{
+		pathParam: func(r *http.Request, name string) (string, bool) {
+			return "", false
+		},
+		text: DefaultTextCodec,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return pkgerrors.Errorf("bind request need non-nil ptr value, but got %T", v)
+	}
+
+	rv, ok := indirectStruct(rv, true)
+	if !ok {
+		return pkgerrors.Errorf("bind request need struct value, but got %T", v)
+	}
+
+	query := r.URL.Query()
+	requestErr := &RequestError{}
+
+	err := eachStructField(rv, TagName, true, func(field types.StructField, name string, omitempty bool, fv reflect.Value) error {
+		in, ok := field.Tag().Lookup(TagIn)
+		if !ok {
+			return nil
+		}
+
+		var values []string
+
+		switch in {
+		case "query":
+			values = query[name]
+		case "header":
+			values = r.Header.Values(name)
+		case "cookie":
+			if c, err := r.Cookie(name); err == nil {
+				values = []string{c.Value}
+			}
+		case "path":
+			if value, ok := o.pathParam(r, name); ok {
+				values = []string{value}
+			}
+		default:
+			return pkgerrors.Errorf("field %s: unknown parameter location %q", field.Name(), in)
+		}
+
+		if len(values) == 0 {
+			return nil
+		}
+
+		if err := o.bind(field, fv, values); err != nil {
+			requestErr.Parameters = append(requestErr.Parameters, &RequestParameterError{
+				In:   in,
+				Name: name,
+				Err:  err,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(requestErr.Parameters) > 0 {
+		return requestErr
+	}
+	return nil
+}
+
+func (o *requestOptions) bind(field types.StructField, fv reflect.Value, values []string) error {
+	tc, err := o.text.ForField(field.Tag())
+	if err != nil {
+		return err
+	}
+
+	if fv.Kind() == reflect.Slice && isTextList(fv.Type()) {
+		list := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i := range values {
+			if err := tc.UnmarshalText(list.Index(i), []byte(values[i])); err != nil {
+				return err
+			}
+		}
+		fv.Set(list)
+		return nil
+	}
+
+	return tc.UnmarshalText(fv, []byte(values[0]))
+}
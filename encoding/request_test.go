@@ -0,0 +1,90 @@
+package encoding
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+)
+
+type Pager struct {
+	Offset int `in:"query" name:"offset"`
+	Limit  int `in:"query" name:"limit"`
+}
+
+type ListRequest struct {
+	Pager
+	ID        int           `in:"path" name:"id"`
+	Tags      []string      `in:"query" name:"tag"`
+	Timeout   time.Duration `in:"header" name:"X-Timeout"`
+	RequestID *string       `in:"header" name:"X-Request-Id"`
+	Session   string        `in:"cookie" name:"session"`
+	Body      string
+}
+
+func pathParams(params map[string]string) RequestOption {
+	return WithPathParam(func(r *http.Request, name string) (string, bool) {
+		v, ok := params[name]
+		return v, ok
+	})
+}
+
+func TestBindRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/items/1?offset=10&limit=20&tag=a&tag=b,c&Body=x", nil)
+	r.Header.Set("X-Timeout", "3s")
+	r.Header.Set("X-Request-Id", "req")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+
+	req := ListRequest{}
+	err := BindRequest(r, &req, pathParams(map[string]string{"id": "1"}))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(req).To(Equal(ListRequest{
+		Pager:     Pager{Offset: 10, Limit: 20},
+		ID:        1,
+		Tags:      []string{"a", "b,c"},
+		Timeout:   3 * time.Second,
+		RequestID: ptr.String("req"),
+		Session:   "s",
+	}))
+
+	t.Run("aggregated errors", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/items/x?offset=a&limit=1&tag=ok", nil)
+		r.Header.Set("X-Timeout", "3")
+
+		err := BindRequest(r, &ListRequest{}, pathParams(map[string]string{"id": "x"}))
+
+		requestErr := &RequestError{}
+		NewWithT(t).Expect(errors.As(err, &requestErr)).To(BeTrue())
+		NewWithT(t).Expect(len(requestErr.Parameters)).To(Equal(3))
+
+		names := make([]string, 0)
+		for _, p := range requestErr.Parameters {
+			names = append(names, p.In+" "+p.Name)
+		}
+		NewWithT(t).Expect(names).To(Equal([]string{"query offset", "path id", "header X-Timeout"}))
+
+		e := &UnmarshalTextError{}
+		NewWithT(t).Expect(errors.As(requestErr.Parameters[0], &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Text).To(Equal("a"))
+
+		NewWithT(t).Expect(strings.Count(err.Error(), ";")).To(Equal(2))
+	})
+
+	t.Run("unknown location", func(t *testing.T) {
+		v := struct {
+			ID int `in:"body" name:"id"`
+		}{}
+		err := BindRequest(httptest.NewRequest(http.MethodGet, "/", nil), &v)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("not ptr", func(t *testing.T) {
+		err := BindRequest(httptest.NewRequest(http.MethodGet, "/", nil), ListRequest{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,98 @@
+package encoding
+
+import (
+	"database/sql/driver"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// SQLText stores V as text column by MarshalText and UnmarshalText,
+// so TextMarshaler types, named integers or durations could be stored without sql.Scanner of each type.
+// When T is a pointer type, nil is stored as NULL and NULL is scanned as nil.
+//
+//	var level encoding.SQLText[Level]
+//	err := db.QueryRow("SELECT level FROM logs").Scan(&level)
+type SQLText[T any] struct {
+	V T
+}
+
+// Value implements driver.Valuer
+func (s SQLText[T]) Value() (driver.Value, error) {
+	if isNil(s.V) {
+		return nil, nil
+	}
+	return marshalSQLText(s.V)
+}
+
+// Scan implements sql.Scanner
+func (s *SQLText[T]) Scan(src any) error {
+	if src == nil {
+		var zero T
+		s.V = zero
+		return nil
+	}
+	return scanSQLText(&s.V, src)
+}
+
+// NullSQLText is SQLText with Valid flag, which is false when NULL, like sql.NullString.
+type NullSQLText[T any] struct {
+	V     T
+	Valid bool
+}
+
+// Value implements driver.Valuer
+func (s NullSQLText[T]) Value() (driver.Value, error) {
+	if !s.Valid || isNil(s.V) {
+		return nil, nil
+	}
+	return marshalSQLText(s.V)
+}
+
+// Scan implements sql.Scanner
+func (s *NullSQLText[T]) Scan(src any) error {
+	var zero T
+	s.V = zero
+
+	if src == nil {
+		s.Valid = false
+		return nil
+	}
+
+	if err := scanSQLText(&s.V, src); err != nil {
+		s.Valid = false
+		return err
+	}
+	s.Valid = true
+	return nil
+}
+
+func marshalSQLText(v any) (driver.Value, error) {
+	text, err := MarshalText(v)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "sql value of %T", v)
+	}
+	return string(text), nil
+}
+
+// scanSQLText decodes src into v, values of driver other than text are converted to text first
+func scanSQLText(v any, src any) error {
+	var data []byte
+
+	switch x := src.(type) {
+	case []byte:
+		data = x
+	case string:
+		data = []byte(x)
+	default:
+		text, err := MarshalText(x)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "scan %T", src)
+		}
+		data = text
+	}
+
+	if err := UnmarshalText(v, data); err != nil {
+		return pkgerrors.Wrapf(err, "scan %T", src)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package encoding
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	sql.Register("encoding-fake", &fakeDriver{tables: map[string][]driver.Value{}})
+}
+
+// fakeDriver stores one column per table in memory,
+// "INSERT <table>" appends the arg, "SELECT <table>" returns all values, text values are returned as []byte.
+type fakeDriver struct {
+	mu     sync.Mutex
+	tables map[string][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	op, table, _ := strings.Cut(query, " ")
+	return &fakeStmt{conn: c, op: op, table: table}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	op    string
+	table string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tables[s.table] = append(d.tables[s.table], args...)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values := make([]driver.Value, len(d.tables[s.table]))
+	copy(values, d.tables[s.table])
+	return &fakeRows{values: values}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	v := r.values[0]
+	r.values = r.values[1:]
+
+	if s, ok := v.(string); ok {
+		v = []byte(s)
+	}
+	dest[0] = v
+	return nil
+}
+
+func roundTripSQL[T any](t *testing.T, table string, values ...T) []T {
+	t.Helper()
+
+	db, err := sql.Open("encoding-fake", "")
+	NewWithT(t).Expect(err).To(BeNil())
+	defer db.Close()
+
+	for _, v := range values {
+		_, err := db.Exec("INSERT "+table, v)
+		NewWithT(t).Expect(err).To(BeNil())
+	}
+
+	rows, err := db.Query("SELECT " + table)
+	NewWithT(t).Expect(err).To(BeNil())
+	defer rows.Close()
+
+	var scanned []T
+	for rows.Next() {
+		var v T
+		NewWithT(t).Expect(rows.Scan(&v)).To(BeNil())
+		scanned = append(scanned, v)
+	}
+	NewWithT(t).Expect(rows.Err()).To(BeNil())
+
+	return scanned
+}
+
+func TestSQLText(t *testing.T) {
+	t.Run("TextMarshaler", func(t *testing.T) {
+		values := []SQLText[Duration]{{V: Duration(time.Second)}, {V: Duration(90 * time.Minute)}}
+		NewWithT(t).Expect(roundTripSQL(t, "durations", values...)).To(Equal(values))
+	})
+
+	t.Run("NamedInt", func(t *testing.T) {
+		values := []SQLText[NamedInt]{{V: 1}, {V: -42}}
+		NewWithT(t).Expect(roundTripSQL(t, "ints", values...)).To(Equal(values))
+	})
+
+	t.Run("Registered", func(t *testing.T) {
+		values := []SQLText[Decimal]{{V: Decimal{Int: 15, Exp: -1}}}
+		NewWithT(t).Expect(roundTripSQL(t, "decimals", values...)).To(Equal(values))
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		values := []SQLText[[]time.Duration]{{V: []time.Duration{time.Second, time.Minute}}}
+		NewWithT(t).Expect(roundTripSQL(t, "slices", values...)).To(Equal(values))
+	})
+
+	t.Run("NullAsNilPointer", func(t *testing.T) {
+		d := Duration(time.Second)
+		values := []SQLText[*Duration]{{V: &d}, {V: nil}}
+		NewWithT(t).Expect(roundTripSQL(t, "ptr_durations", values...)).To(Equal(values))
+
+		v, err := SQLText[*Duration]{}.Value()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(v).To(BeNil())
+	})
+
+	t.Run("StoredAsText", func(t *testing.T) {
+		v, err := SQLText[Duration]{V: Duration(time.Second)}.Value()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(v).To(Equal("1s"))
+	})
+
+	t.Run("ScanDriverValue", func(t *testing.T) {
+		var i SQLText[NamedInt]
+		NewWithT(t).Expect(i.Scan(int64(7))).To(BeNil())
+		NewWithT(t).Expect(i.V).To(Equal(NamedInt(7)))
+
+		var b SQLText[bool]
+		NewWithT(t).Expect(b.Scan(true)).To(BeNil())
+		NewWithT(t).Expect(b.V).To(BeTrue())
+	})
+
+	t.Run("ScanInvalid", func(t *testing.T) {
+		var d SQLText[Duration]
+		NewWithT(t).Expect(d.Scan("x")).NotTo(BeNil())
+	})
+}
+
+func TestNullSQLText(t *testing.T) {
+	values := []NullSQLText[Duration]{
+		{V: Duration(time.Second), Valid: true},
+		{},
+		{V: Duration(time.Minute), Valid: true},
+	}
+	NewWithT(t).Expect(roundTripSQL(t, "null_durations", values...)).To(Equal(values))
+
+	t.Run("InvalidStoredAsNull", func(t *testing.T) {
+		v, err := NullSQLText[Duration]{V: Duration(time.Second)}.Value()
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(v).To(BeNil())
+	})
+
+	t.Run("ScanNullResets", func(t *testing.T) {
+		s := NullSQLText[NamedInt]{V: 1, Valid: true}
+		NewWithT(t).Expect(s.Scan(nil)).To(BeNil())
+		NewWithT(t).Expect(s).To(Equal(NullSQLText[NamedInt]{}))
+	})
+
+	t.Run("ScanInvalid", func(t *testing.T) {
+		var s NullSQLText[NamedInt]
+		NewWithT(t).Expect(s.Scan("x")).NotTo(BeNil())
+		NewWithT(t).Expect(s.Valid).To(BeFalse())
+	})
+}
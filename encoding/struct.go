@@ -0,0 +1,192 @@
+package encoding
+
+import (
+	"encoding"
+	"go/ast"
+	"reflect"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// eachStructField walks fields of struct rv like types.EachField, with the value of each field.
+// nil embedded pointers are allocated when alloc, otherwise fields under them are skipped.
+// the walk stops at the first error of each.
+func eachStructField(rv reflect.Value, tagForName string, alloc bool, each func(field types.StructField, fieldDisplayName string, omitempty bool, fv reflect.Value) error) (err error) {
+	walkStructFields(rv.Type(), tagForName, nil, func(field types.StructField, fieldDisplayName string, omitempty bool, index []int) bool {
+		fv, ok := fieldByIndex(rv, index, alloc)
+		if !ok {
+			return true
+		}
+		err = each(field, fieldDisplayName, omitempty, fv)
+		return err == nil
+	})
+	return
+}
+
+// walkStructFields walks fields of struct typ like types.EachField, with index path of each field from the root,
+// so shadowed or ambiguous fields of embedded structs are resolved as the field yielded, not by name.
+// returns false when each returns false, which stops the walk of embedded structs too.
+func walkStructFields(typ reflect.Type, tagForName string, index []int, each func(field types.StructField, fieldDisplayName string, omitempty bool, index []int) bool) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+
+		fieldDisplayName, omitempty, keepNested := types.FieldDisplayName(sf.Tag, tagForName, sf.Name)
+		if !ast.IsExported(sf.Name) || fieldDisplayName == "-" {
+			continue
+		}
+
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		if sf.Anonymous {
+			switch fieldType := reflectx.Deref(sf.Type); fieldType.Kind() {
+			case reflect.Struct:
+				if !keepNested {
+					if !walkStructFields(fieldType, tagForName, fieldIndex, each) {
+						return false
+					}
+					continue
+				}
+			case reflect.Interface:
+				continue
+			}
+		}
+
+		if !each(&types.RStructField{StructField: sf}, fieldDisplayName, omitempty, fieldIndex) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldByIndex returns the field of struct rv by index path, pointers on the path are dereferenced.
+// returns false when pointer on the path is nil and could not be allocated.
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					if !alloc || !rv.CanSet() {
+						return reflect.Value{}, false
+					}
+					rv.Set(reflect.New(rv.Type().Elem()))
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, true
+}
+
+func indirectStruct(v any, alloc bool) (reflect.Value, bool) {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			if !alloc || !rv.CanSet() {
+				return reflect.Value{}, false
+			}
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	return rv, rv.Kind() == reflect.Struct
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isTextList reports whether values of typ should be handled element by element,
+// as lists which are not []byte or text (un)marshaler themselves.
+func isTextList(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if reflectx.IsBytes(typ) {
+			return false
+		}
+		return !typ.Implements(textMarshalerType) && !reflect.PtrTo(typ).Implements(textUnmarshalerType)
+	}
+	return false
+}
+
+var (
+	timeType              = reflect.TypeOf(time.Time{})
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// isNestedStruct reports whether typ (or elem of ptr typ) is struct which should be walked field by field,
+// instead of converted as text.
+func (c *TextCodec) isNestedStruct(typ reflect.Type) bool {
+	typ = reflectx.Deref(typ)
+	if typ.Kind() != reflect.Struct || typ == timeType || c.registry.has(typ) {
+		return false
+	}
+	ptrType := reflect.PtrTo(typ)
+	return !ptrType.Implements(textUnmarshalerType) && !ptrType.Implements(binaryUnmarshalerType)
+}
+
+// structColumn is leaf field of struct, which is converted as text directly
+type structColumn struct {
+	name      string
+	index     []int
+	tc        *TextCodec
+	codec     *TypeCodec
+	omitempty bool
+	required  bool
+}
+
+// structColumnsOf returns columns of struct typ named by tag, nested structs are flattened as `a.b` columns.
+// index of each column is the path from the root struct, with the index of typ prefixed.
+func structColumnsOf(typ reflect.Type, tag string, prefix string, index []int) ([]*structColumn, error) {
+	columns := make([]*structColumn, 0)
+
+	var err error
+
+	walkStructFields(typ, tag, index, func(field types.StructField, fieldDisplayName string, omitempty bool, fieldIndex []int) bool {
+		fieldType := field.(*types.RStructField).StructField.Type
+		name := flattenKey(prefix, fieldDisplayName)
+
+		if DefaultTextCodec.isNestedStruct(fieldType) {
+			nested, e := structColumnsOf(reflectx.Deref(fieldType), tag, name, fieldIndex)
+			if e != nil {
+				err = e
+				return false
+			}
+			columns = append(columns, nested...)
+			return true
+		}
+
+		tc, e := DefaultTextCodec.ForField(field.Tag())
+		if e != nil {
+			err = pkgerrors.Wrapf(e, "%s", name)
+			return false
+		}
+
+		columns = append(columns, &structColumn{
+			name:      name,
+			index:     fieldIndex,
+			tc:        tc,
+			codec:     tc.codecFor(fieldType),
+			omitempty: omitempty,
+			required:  reflectx.StructTag(field.Tag().Get(tag)).HasFlag("required"),
+		})
+		return true
+	})
+
+	return columns, err
+}
+
+// value returns field of struct rv by index of column.
+// returns false when nested nil pointer is not allocated
+func (c *structColumn) value(rv reflect.Value, alloc bool) (reflect.Value, bool) {
+	return fieldByIndex(rv, c.index, alloc)
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+type ValidateDB struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"min=1,max=65535"`
+}
+
+type ValidateMeta struct {
+	Owner string `json:"owner" validate:"omitempty,pattern=^[a-z]+$"`
+}
+
+type ValidateConfig struct {
+	ValidateMeta
+	Name    string        `json:"name" validate:"required,min=2,max=8"`
+	Mode    string        `json:"mode" validate:"omitempty,oneof=dev|prod"`
+	Timeout time.Duration `json:"timeout" validate:"min=1s,max=1m"`
+	Ratio   *float64      `json:"ratio" validate:"max=1"`
+	Tags    []string      `json:"tags" validate:"max=2,oneof=a|b|c"`
+	DB      ValidateDB    `json:"db"`
+	Replica *ValidateDB   `json:"replica"`
+}
@@ -1,305 +1,440 @@
 package encoding
 
 import (
-	"bytes"
-	"encoding"
-	"encoding/base64"
-	"fmt"
-	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	pkgerrors "github.com/pkg/errors"
-	reflectx "github.com/utilsgo/x/reflect"
 )
 
-func MarshalText(v any) ([]byte, error) {
-	if rv, ok := v.(reflect.Value); ok {
-		for rv.Kind() == reflect.Ptr {
-			if rv.IsNil() {
-				return nil, nil
+// TextAppender is the interface implemented by an object
+// that can append the textual representation of itself.
+// same as encoding.TextAppender of go1.24
+type TextAppender interface {
+	AppendText(b []byte) ([]byte, error)
+}
+
+// TextCodec converts values to and from text.
+type TextCodec struct {
+	separator         byte
+	keyValueSeparator byte
+	escape            byte
+	bytesEncoding     BytesEncoding
+	timeLayout        string
+	intBase           int
+	boolVocabulary    *BoolVocabulary
+	nullPolicy        NullPolicy
+	nullText          []byte
+	registry          *TextCodecRegistry
+	codecs            *typeCodecs
+}
+
+type TextCodecOption func(c *TextCodec)
+
+// WithSeparator sets separator between elements of slice, array and map. default ','
+func WithSeparator(sep byte) TextCodecOption {
+	return func(c *TextCodec) {
+		c.separator = sep
+	}
+}
+
+// WithKeyValueSeparator sets separator between key and value of map entry. default '='
+func WithKeyValueSeparator(sep byte) TextCodecOption {
+	return func(c *TextCodec) {
+		c.keyValueSeparator = sep
+	}
+}
+
+// WithEscape sets escape char for separators in elements. default '\\'
+func WithEscape(escape byte) TextCodecOption {
+	return func(c *TextCodec) {
+		c.escape = escape
+	}
+}
+
+// WithBytesEncoding sets representation of []byte. default BytesBase64
+func WithBytesEncoding(enc BytesEncoding) TextCodecOption {
+	return func(c *TextCodec) {
+		c.bytesEncoding = enc
+	}
+}
+
+// WithTimeLayout sets layout of time.Time. default time.RFC3339Nano
+func WithTimeLayout(layout string) TextCodecOption {
+	return func(c *TextCodec) {
+		c.timeLayout = layout
+	}
+}
+
+// WithIntBase sets base of integers. default 10
+// 2, 8 and 16 are encoded with prefix 0b, 0o and 0x, like file modes in octal.
+// With base 0, integers are decoded as Go literals,
+// base from prefix and underscores allowed, like 0x1F, 0o755, 0b1010 or 1_000_000, but encoded as decimal.
+// With base 2, 8 or 16, digits without prefix are decoded in the base, like 755 of base 8,
+// and literals with prefix are accepted too, except 0b of base 16 which are hex digits.
+func WithIntBase(base int) TextCodecOption {
+	return func(c *TextCodec) {
+		c.intBase = base
+	}
+}
+
+// WithBoolVocabulary sets words of bool for lenient decoding, like LenientBoolVocabulary.
+// default nil, bool is decoded by strconv.ParseBool
+func WithBoolVocabulary(v *BoolVocabulary) TextCodecOption {
+	return func(c *TextCodec) {
+		c.boolVocabulary = v
+	}
+}
+
+// WithNullPolicy sets how nil values are converted. default NullAsEmpty
+func WithNullPolicy(policy NullPolicy) TextCodecOption {
+	return func(c *TextCodec) {
+		c.nullPolicy = policy
+	}
+}
+
+// WithNullText sets null text and NullAsText as NullPolicy
+func WithNullText(text string) TextCodecOption {
+	return func(c *TextCodec) {
+		c.nullPolicy = NullAsText
+		c.nullText = []byte(text)
+	}
+}
+
+// WithRegistry sets registry of text codecs for third-party types. default DefaultTextCodecRegistry
+func WithRegistry(r *TextCodecRegistry) TextCodecOption {
+	return func(c *TextCodec) {
+		c.registry = r
+	}
+}
+
+func NewTextCodec(opts ...TextCodecOption) *TextCodec {
+	c := &TextCodec{
+		separator:         ',',
+		keyValueSeparator: '=',
+		escape:            '\\',
+		timeLayout:        time.RFC3339Nano,
+		intBase:           10,
+		nullText:          []byte(DefaultNullText),
+		registry:          DefaultTextCodecRegistry,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.codecs = &typeCodecs{}
+	return c
+}
+
+var DefaultTextCodec = NewTextCodec()
+
+// TagText is the struct tag to override options of TextCodec for single field.
+//
+//	Digest  []byte `text:"bytes=hex"`
+//	Mode    uint32 `text:"base=8"`
+const TagText = "text"
+
+// ForField returns TextCodec with options overridden by the `text` tag of field.
+// Options are comma separated key=value pairs:
+//
+//	bytes: std, url, rawstd, rawurl, hex or base32
+//	base: base of integers, 0, 2, 8, 10 or 16, see WithIntBase
+//	bool: lenient, bool is decoded by LenientBoolVocabulary
+//	layout: layout of time.Time, must be the last option as layout may contain ','
+func (c *TextCodec) ForField(tag reflect.StructTag) (*TextCodec, error) {
+	value, ok := tag.Lookup(TagText)
+	if !ok || value == "" {
+		return c, nil
+	}
+
+	cache := c.typeCodecCache()
+	if fc, ok := cache.fields.Load(value); ok {
+		return fc.(*TextCodec), nil
+	}
+
+	fc := *c
+	fc.codecs = &typeCodecs{}
+	tagValue := value
+
+	for value != "" {
+		var option string
+		option, value, _ = strings.Cut(value, ",")
+		key, val, _ := strings.Cut(option, "=")
+
+		switch key {
+		case "bytes":
+			enc, err := ParseBytesEncoding(val)
+			if err != nil {
+				return nil, err
 			}
-			rv = rv.Elem()
+			fc.bytesEncoding = enc
+		case "base":
+			base, err := parseIntBase(val)
+			if err != nil {
+				return nil, err
+			}
+			fc.intBase = base
+		case "bool":
+			if val != "lenient" {
+				return nil, pkgerrors.Errorf("unsupported bool option %q", val)
+			}
+			fc.boolVocabulary = LenientBoolVocabulary
+		case "layout":
+			if value != "" {
+				val, value = val+","+value, ""
+			}
+			fc.timeLayout = val
+		default:
+			return nil, pkgerrors.Errorf("unknown text tag option %q", option)
 		}
+	}
 
-		if rv.CanInterface() {
-			v = rv.Interface()
+	cache.fields.Store(tagValue, &fc)
+	return &fc, nil
+}
+
+func MarshalText(v any) ([]byte, error) {
+	return DefaultTextCodec.MarshalText(v)
+}
+
+// AppendText appends text of v to dst by DefaultTextCodec
+func AppendText(dst []byte, v any) ([]byte, error) {
+	return DefaultTextCodec.AppendText(dst, v)
+}
+
+func UnmarshalText(v any, data []byte) error {
+	return DefaultTextCodec.UnmarshalText(v, data)
+}
+
+func (c *TextCodec) MarshalText(v any) ([]byte, error) {
+	if text, ok := c.appendBasic([]byte{}, v); ok {
+		return text, nil
+	}
+	if isNil(v) {
+		if c.nullPolicy == NullAsText {
+			return c.appendNull([]byte{}), nil
 		}
+		return nil, nil
+	}
+	return c.AppendText([]byte{}, v)
+}
+
+// AppendText appends text of v to dst and returns the extended buffer.
+// nil value appends nothing, or the null text when NullAsText.
+func (c *TextCodec) AppendText(dst []byte, v any) ([]byte, error) {
+	if d, ok := c.appendBasic(dst, v); ok {
+		return d, nil
 	}
 
-	if textMarshaler, ok := v.(encoding.TextMarshaler); ok {
-		return textMarshaler.MarshalText()
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
 	}
 
-	if v == nil {
-		return nil, nil
+	if !rv.IsValid() {
+		return c.appendNull(dst), nil
+	}
+
+	return c.codecFor(rv.Type()).appendValue(dst, rv)
+}
+
+// UnmarshalText decodes data into v, which should be pointer or settable reflect.Value.
+// When NullAsText, the null text sets nil to the pointer instead of allocating.
+func (c *TextCodec) UnmarshalText(v any, data []byte) error {
+	if ok, err := c.unmarshalBasic(v, data); ok {
+		return err
+	}
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+
+	if rv.Kind() == reflect.Ptr && !rv.CanSet() {
+		if rv.IsNil() {
+			return pkgerrors.Errorf("unmarshal text need non-nil ptr value, but got %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.CanAddr() {
+		if !rv.IsValid() {
+			return pkgerrors.Errorf("unmarshal text need ptr value, but got %#v", v)
+		}
+		return pkgerrors.Errorf("unmarshal text need ptr value, but got %s", rv.Type())
+	}
+
+	return c.codecFor(rv.Type()).unmarshalValue(rv, data)
+}
+
+// appendBasic appends text of v of basic types by type switch, which is cheaper than lookup of TypeCodec.
+// returns false for other types, or when codec of any basic type is registered.
+func (c *TextCodec) appendBasic(dst []byte, v any) ([]byte, bool) {
+	if c.registry.basic.Load() {
+		return nil, false
 	}
 
 	switch x := v.(type) {
-	case []byte:
-		return toBase64Encoded(x), nil
 	case string:
-		return []byte(x), nil
+		return append(dst, x...), true
 	case bool:
-		return strconv.AppendBool([]byte{}, x), nil
+		return strconv.AppendBool(dst, x), true
 	case int:
-		return strconv.AppendInt([]byte{}, int64(x), 10), nil
+		return c.appendInt(dst, int64(x)), true
 	case int8:
-		return strconv.AppendInt([]byte{}, int64(x), 10), nil
+		return c.appendInt(dst, int64(x)), true
 	case int16:
-		return strconv.AppendInt([]byte{}, int64(x), 10), nil
+		return c.appendInt(dst, int64(x)), true
 	case int32:
-		return strconv.AppendInt([]byte{}, int64(x), 10), nil
+		return c.appendInt(dst, int64(x)), true
 	case int64:
-		return strconv.AppendInt([]byte{}, x, 10), nil
+		return c.appendInt(dst, x), true
 	case uint:
-		return strconv.AppendUint([]byte{}, uint64(x), 10), nil
+		return c.appendUint(dst, uint64(x)), true
 	case uint8:
-		return strconv.AppendUint([]byte{}, uint64(x), 10), nil
+		return c.appendUint(dst, uint64(x)), true
 	case uint16:
-		return strconv.AppendUint([]byte{}, uint64(x), 10), nil
+		return c.appendUint(dst, uint64(x)), true
 	case uint32:
-		return strconv.AppendUint([]byte{}, uint64(x), 10), nil
+		return c.appendUint(dst, uint64(x)), true
 	case uint64:
-		return strconv.AppendUint([]byte{}, x, 10), nil
+		return c.appendUint(dst, x), true
+	case uintptr:
+		return c.appendUint(dst, uint64(x)), true
 	case float32:
-		return strconv.AppendFloat([]byte{}, float64(x), 'g', -1, 32), nil
+		return strconv.AppendFloat(dst, float64(x), 'g', -1, 32), true
 	case float64:
-		return strconv.AppendFloat([]byte{}, x, 'g', -1, 64), nil
-	default:
-		rv := reflect.ValueOf(x)
-
-		for rv.Kind() == reflect.Ptr {
-			if rv.IsNil() {
-				return nil, nil
-			}
-			rv = rv.Elem()
-		}
-
-		switch rv.Kind() {
-		case reflect.Slice:
-			if et := rv.Type().Elem(); et.PkgPath() == "" && et.Kind() == reflect.Uint8 {
-				return toBase64Encoded(rv.Bytes()), nil
-			}
-		case reflect.String:
-			return []byte(rv.String()), nil
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			return strconv.AppendInt([]byte{}, rv.Int(), 10), nil
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			return strconv.AppendUint([]byte{}, rv.Uint(), 10), nil
-		case reflect.Float32:
-			return strconv.AppendFloat([]byte{}, rv.Float(), 'g', -1, 32), nil
-		case reflect.Float64:
-			return strconv.AppendFloat([]byte{}, rv.Float(), 'g', -1, 64), nil
-		case reflect.Bool:
-			return strconv.AppendBool([]byte{}, rv.Bool()), nil
-		}
-
-		return nil, fmt.Errorf("unsupported type %T", x)
+		return strconv.AppendFloat(dst, x, 'g', -1, 64), true
+	case complex64:
+		return appendComplex(dst, complex128(x), 64), true
+	case complex128:
+		return appendComplex(dst, x, 128), true
 	}
+	return nil, false
 }
 
-func UnmarshalText(v any, data []byte) error {
-	if rv, ok := v.(reflect.Value); ok {
-		if rv.Kind() != reflect.Ptr {
-			rv = rv.Addr()
-		} else {
-			if rv.IsNil() {
-				rv.Set(reflectx.New(rv.Type()))
-			}
-		}
-
-		if rv.CanInterface() {
-			if textUnmarshaler, ok := rv.Interface().(encoding.TextUnmarshaler); ok {
-				if err := textUnmarshaler.UnmarshalText(data); err != nil {
-					return pkgerrors.Wrapf(err, "unmarshal text to %T failed", v)
-				}
-				return nil
-			}
-		}
-
-		return unmarshalTextToReflectValue(rv, data)
-	}
-
-	if textUnmarshaler, ok := v.(encoding.TextUnmarshaler); ok {
-		if err := textUnmarshaler.UnmarshalText(data); err != nil {
-			return pkgerrors.Wrapf(err, "unmarshal text to %T failed", v)
-		}
-		return nil
-	}
-
-	if v == nil {
-		return UnmarshalText(reflect.ValueOf(v), data)
+// unmarshalBasic decodes data into v of pointer to basic types by type switch, like appendBasic.
+// returns false for other types or nil pointer, or when codec of any basic type is registered.
+func (c *TextCodec) unmarshalBasic(v any, data []byte) (bool, error) {
+	if c.registry.basic.Load() {
+		return false, nil
 	}
 
 	switch x := v.(type) {
-	case *[]byte:
-		d, err := fromBase64Encoded(data)
-		if err != nil {
-			return err
-		}
-		*x = d
 	case *string:
-		*x = string(data)
+		return unmarshalBasicTo(x, data, func(data []byte) (string, error) {
+			return string(data), nil
+		})
 	case *bool:
-		v, err := strconv.ParseBool(string(data))
-		if err != nil {
-			return pkgerrors.Wrapf(err, "unmarshal text")
-		}
-		*x = v
+		return unmarshalBasicTo(x, data, c.parseBool)
 	case *int:
-		i, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = int(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (int, error) {
+			i, err := c.parseInt(data, strconv.IntSize)
+			return int(i), err
+		})
 	case *int8:
-		i, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = int8(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (int8, error) {
+			i, err := c.parseInt(data, 8)
+			return int8(i), err
+		})
 	case *int16:
-		i, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = int16(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (int16, error) {
+			i, err := c.parseInt(data, 16)
+			return int16(i), err
+		})
 	case *int32:
-		i, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = int32(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (int32, error) {
+			i, err := c.parseInt(data, 32)
+			return int32(i), err
+		})
 	case *int64:
-		i, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = i
+		return unmarshalBasicTo(x, data, func(data []byte) (int64, error) {
+			return c.parseInt(data, 64)
+		})
 	case *uint:
-		i, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = uint(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (uint, error) {
+			u, err := c.parseUint(data, strconv.IntSize)
+			return uint(u), err
+		})
 	case *uint8:
-		i, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = uint8(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (uint8, error) {
+			u, err := c.parseUint(data, 8)
+			return uint8(u), err
+		})
 	case *uint16:
-		i, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = uint16(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (uint16, error) {
+			u, err := c.parseUint(data, 16)
+			return uint16(u), err
+		})
 	case *uint32:
-		i, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = uint32(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (uint32, error) {
+			u, err := c.parseUint(data, 32)
+			return uint32(u), err
+		})
 	case *uint64:
-		i, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = i
+		return unmarshalBasicTo(x, data, func(data []byte) (uint64, error) {
+			return c.parseUint(data, 64)
+		})
 	case *float32:
-		i, err := strconv.ParseFloat(string(data), 32)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = float32(i)
+		return unmarshalBasicTo(x, data, func(data []byte) (float32, error) {
+			f, err := strconv.ParseFloat(string(data), 32)
+			return float32(f), err
+		})
 	case *float64:
-		i, err := strconv.ParseFloat(string(data), 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		*x = i
-	default:
-		return unmarshalTextToReflectValue(reflect.ValueOf(x), data)
+		return unmarshalBasicTo(x, data, func(data []byte) (float64, error) {
+			return strconv.ParseFloat(string(data), 64)
+		})
 	}
-	return nil
+	return false, nil
 }
 
-func unmarshalTextToReflectValue(rv reflect.Value, data []byte) error {
-	if rv.Kind() != reflect.Ptr {
-		return pkgerrors.Errorf("unmarshal text need ptr value, but got %#v", rv.Interface())
+// unmarshalBasicTo sets x by parse, x is unchanged on error
+func unmarshalBasicTo[T any](x *T, data []byte, parse func(data []byte) (T, error)) (bool, error) {
+	if x == nil {
+		return false, nil
+	}
+	v, err := parse(data)
+	if err != nil {
+		return true, newUnmarshalTextError(reflect.TypeOf(x).Elem(), data, err)
+	}
+	*x = v
+	return true, nil
+}
+
+// isNil reports whether v is nil or nil pointer
+func isNil(v any) bool {
+	var rv reflect.Value
+
+	switch x := v.(type) {
+	case nil:
+		return true
+	case reflect.Value:
+		rv = x
+	default:
+		rv = reflect.ValueOf(x)
 	}
 
 	for rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
-			rv.Set(reflectx.New(rv.Type()))
+			return true
 		}
 		rv = rv.Elem()
 	}
 
-	switch rv.Kind() {
-	case reflect.Slice:
-		et := rv.Type().Elem()
-		if et.PkgPath() == "" && et.Kind() == reflect.Uint8 {
-			d, err := fromBase64Encoded(data)
-			if err != nil {
-				return err
-			}
-			rv.SetBytes(d)
-		}
-	case reflect.String:
-		rv.SetString(string(data))
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intV, err := strconv.ParseInt(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		rv.SetInt(intV)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintV, err := strconv.ParseUint(string(data), 10, 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		rv.SetUint(uintV)
-	case reflect.Float32, reflect.Float64:
-		floatV, err := strconv.ParseFloat(string(data), 64)
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		rv.SetFloat(floatV)
-	case reflect.Bool:
-		boolV, err := strconv.ParseBool(string(data))
-		if err != nil {
-			return pkgerrors.Wrap(err, "unmarshal text")
-		}
-		rv.SetBool(boolV)
-	}
-	return nil
+	return false
 }
 
-// flow encoding/json did
-func fromBase64Encoded(data []byte) ([]byte, error) {
-	encodedLen := base64.StdEncoding.EncodedLen(len(data))
-	d := make([]byte, encodedLen)
-	n, err := base64.StdEncoding.Decode(d, data)
-	if err != nil {
-		return nil, err
+// appendComplex appends complex like strconv.FormatComplex
+func appendComplex(dst []byte, c complex128, bitSize int) []byte {
+	dst = append(dst, '(')
+	dst = strconv.AppendFloat(dst, real(c), 'g', -1, bitSize/2)
+	i := len(dst)
+	dst = strconv.AppendFloat(dst, imag(c), 'g', -1, bitSize/2)
+	if dst[i] != '+' && dst[i] != '-' {
+		dst = append(dst, 0)
+		copy(dst[i+1:], dst[i:])
+		dst[i] = '+'
 	}
-	return d[:n], nil
-}
-
-// flow encoding/json did
-func toBase64Encoded(data []byte) []byte {
-	encodedLen := base64.StdEncoding.EncodedLen(len(data))
-	if encodedLen <= 1024 {
-		d := make([]byte, encodedLen)
-		base64.StdEncoding.Encode(d, data)
-		return d
-	}
-	output := bytes.NewBuffer(nil)
-	r := bytes.NewBuffer(data)
-	enc := base64.NewEncoder(base64.StdEncoding, output)
-	_, _ = io.Copy(enc, r)
-	_ = enc.Close()
-	return output.Bytes()
+	return append(dst, 'i', ')')
 }
@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -33,6 +34,7 @@ func (d *Duration) UnmarshalText(data []byte) error {
 type (
 	NamedString string
 	NamedInt    int
+	NamedInt8   int8
 )
 
 var longBytes = strings.Join(slices.Map(make([]string, 1025), func(e string) string {
@@ -264,12 +266,19 @@ func TestUnmarshalTextAndMarshalText(t *testing.T) {
 	v2 := struct {
 		PtrString *string
 		Slice     []string
+		Chan      chan string
 	}{}
 
 	rv2 := reflect.ValueOf(v2)
 
 	{
-		_, err := MarshalText(rv2.FieldByName("Slice"))
+		text, err := MarshalText(rv2.FieldByName("Slice"))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(text).To(BeEmpty())
+	}
+
+	{
+		_, err := MarshalText(rv2.FieldByName("Chan"))
 		NewWithT(t).Expect(err).NotTo(BeNil())
 	}
 
@@ -278,3 +287,348 @@ func TestUnmarshalTextAndMarshalText(t *testing.T) {
 		NewWithT(t).Expect(err).To(BeNil())
 	}
 }
+
+func TestUnmarshalTextOverflow(t *testing.T) {
+	v := struct {
+		Int8    int8
+		Int16   int16
+		Int32   int32
+		Uint8   uint8
+		Uint16  uint16
+		Uint32  uint32
+		Float32 float32
+	}{}
+
+	rv := reflect.ValueOf(&v).Elem()
+
+	cases := []struct {
+		name string
+		v    any
+		text string
+	}{
+		{"int8", ptr.Int8(0), "300"},
+		{"int8 negative", ptr.Int8(0), "-129"},
+		{"int16", ptr.Int16(0), "32768"},
+		{"int32", ptr.Int32(0), "2147483648"},
+		{"uint8", ptr.Uint8(0), "256"},
+		{"uint16", ptr.Uint16(0), "65536"},
+		{"uint32", ptr.Uint32(0), "4294967296"},
+		{"float32", ptr.Float32(0), "1e39"},
+		{"reflect int8", rv.FieldByName("Int8"), "300"},
+		{"reflect int16", rv.FieldByName("Int16"), "32768"},
+		{"reflect int32", rv.FieldByName("Int32"), "2147483648"},
+		{"reflect uint8", rv.FieldByName("Uint8"), "256"},
+		{"reflect uint16", rv.FieldByName("Uint16"), "65536"},
+		{"reflect uint32", rv.FieldByName("Uint32"), "4294967296"},
+		{"reflect float32", rv.FieldByName("Float32"), "1e39"},
+		{"named int8", ptr.Ptr(NamedInt8(0)), "128"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := UnmarshalText(c.v, []byte(c.text))
+
+			e := &UnmarshalTextError{}
+			NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+			NewWithT(t).Expect(e.Text).To(Equal(c.text))
+			NewWithT(t).Expect(errors.Is(err, strconv.ErrRange)).To(BeTrue())
+		})
+	}
+}
+
+func TestUnmarshalTextError(t *testing.T) {
+	t.Run("syntax", func(t *testing.T) {
+		err := UnmarshalText(ptr.Int(0), []byte("x"))
+
+		e := &UnmarshalTextError{}
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Type).To(Equal(reflect.TypeOf(0)))
+		NewWithT(t).Expect(e.Text).To(Equal("x"))
+		NewWithT(t).Expect(errors.Is(err, strconv.ErrSyntax)).To(BeTrue())
+	})
+
+	t.Run("TextUnmarshaler", func(t *testing.T) {
+		d := Duration(0)
+		err := UnmarshalText(&d, []byte("x"))
+
+		e := &UnmarshalTextError{}
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Type).To(Equal(reflect.TypeOf(d)))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		err := UnmarshalText(rv.FieldByName("Bytes"), []byte("!"))
+
+		e := &UnmarshalTextError{}
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Type).To(Equal(reflect.TypeOf([]byte{})))
+	})
+}
+
+func TestTextCodecList(t *testing.T) {
+	v := struct {
+		Ints      []int
+		Strings   [3]string
+		Durations []Duration
+		NamedInts []NamedInt
+		Map       map[string]int
+		IntMap    map[NamedInt][]string
+		Nested    [][]string
+	}{}
+
+	rv := reflect.ValueOf(&v).Elem()
+
+	cases := []struct {
+		name   string
+		v      any
+		text   string
+		expect any
+	}{
+		{
+			"Slice",
+			rv.FieldByName("Ints"),
+			"1,2,3",
+			[]int{1, 2, 3},
+		},
+		{
+			"Empty Slice",
+			rv.FieldByName("Ints"),
+			"",
+			[]int{},
+		},
+		{
+			"Array",
+			rv.FieldByName("Strings"),
+			"a,b\\,c,",
+			[3]string{"a", "b,c", ""},
+		},
+		{
+			"TextMarshaler elements",
+			rv.FieldByName("Durations"),
+			"1s,2m0s",
+			[]Duration{Duration(time.Second), Duration(2 * time.Minute)},
+		},
+		{
+			"Named elements",
+			rv.FieldByName("NamedInts"),
+			"1,2",
+			[]NamedInt{1, 2},
+		},
+		{
+			"Map",
+			rv.FieldByName("Map"),
+			"a=1,b\\=c=2",
+			map[string]int{"a": 1, "b=c": 2},
+		},
+		{
+			"Map with slice values",
+			rv.FieldByName("IntMap"),
+			"1=a\\,b,2=c",
+			map[NamedInt][]string{1: {"a", "b"}, 2: {"c"}},
+		},
+		{
+			"Nested",
+			rv.FieldByName("Nested"),
+			"a\\,b\\\\\\,c,d",
+			[][]string{{"a", "b,c"}, {"d"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := UnmarshalText(c.v, []byte(c.text))
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(c.v.(reflect.Value).Interface()).To(Equal(c.expect))
+
+			text, err := MarshalText(c.v)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(c.text))
+		})
+	}
+
+	t.Run("direct", func(t *testing.T) {
+		text, err := MarshalText([]int{1, 2})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("1,2"))
+
+		ints := make([]int, 0)
+		err = UnmarshalText(&ints, []byte("3,4"))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ints).To(Equal([]int{3, 4}))
+	})
+
+	t.Run("custom separators", func(t *testing.T) {
+		c := NewTextCodec(WithSeparator(';'), WithKeyValueSeparator(':'), WithEscape('^'))
+
+		text, err := c.MarshalText(map[string][]int{"a;b": {1, 2}})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("a^;b:1^;2"))
+
+		m := map[string][]int{}
+		err = c.UnmarshalText(&m, text)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(m).To(Equal(map[string][]int{"a;b": {1, 2}}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		e := &UnmarshalTextError{}
+
+		err := UnmarshalText(rv.FieldByName("Strings"), []byte("a,b,c,d"))
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+
+		err = UnmarshalText(rv.FieldByName("Map"), []byte("a"))
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+
+		err = UnmarshalText(rv.FieldByName("Ints"), []byte("1,x"))
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+		NewWithT(t).Expect(e.Text).To(Equal("x"))
+	})
+}
+
+type TextEmbedded struct {
+	Host string `json:"host"`
+}
+
+type TextStruct struct {
+	*TextEmbedded
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags,omitempty"`
+	Mode  uint32   `json:"mode" text:"base=8"`
+	Port  int
+	Inner struct {
+		A string `json:"a"`
+	} `json:"inner"`
+	Ignored string `json:"-"`
+}
+
+func TestTextCodecStruct(t *testing.T) {
+	v := TextStruct{
+		TextEmbedded: &TextEmbedded{Host: "h"},
+		Name:         "a,b",
+		Tags:         []string{"x", "y"},
+		Mode:         0o755,
+		Port:         80,
+	}
+	v.Inner.A = "="
+
+	text, err := MarshalText(v)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(text)).To(Equal(`Port=80,host=h,inner=a\=\\\=,mode=0o755,name=a\,b,tags=x\,y`))
+
+	decoded := TextStruct{}
+	NewWithT(t).Expect(UnmarshalText(&decoded, append(text, ",unknown=1"...))).To(BeNil())
+	NewWithT(t).Expect(decoded).To(Equal(v))
+
+	t.Run("skip empty", func(t *testing.T) {
+		text, err := MarshalText(TextStruct{})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal(`Port=0,inner=a\=,mode=0o0,name=`))
+	})
+
+	t.Run("duplicated names", func(t *testing.T) {
+		_, err := MarshalText(struct {
+			TextEmbedded
+			Host string `json:"host"`
+		}{})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		e := &UnmarshalTextError{}
+
+		err := UnmarshalText(&TextStruct{}, []byte("Port=x"))
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+
+		err = UnmarshalText(&TextStruct{}, []byte("Port"))
+		NewWithT(t).Expect(errors.As(err, &e)).To(BeTrue())
+	})
+}
+
+func TestTextCodecBuiltinTypes(t *testing.T) {
+	tm := time.Date(2024, 5, 24, 10, 30, 0, 500, time.UTC)
+
+	v := struct {
+		Complex64     complex64
+		Complex128    complex128
+		Uintptr       uintptr
+		Timeout       time.Duration
+		PtrTimeout    *time.Duration
+		PtrPtrTimeout **time.Duration
+		Time          time.Time
+		PtrTime       *time.Time
+		Timeouts      []time.Duration
+		PtrPtrDur     **Duration
+	}{}
+
+	rv := reflect.ValueOf(&v).Elem()
+
+	cases := []struct {
+		name   string
+		v      any
+		text   string
+		expect any
+	}{
+		{"complex64", rv.FieldByName("Complex64"), "(1+2i)", complex64(1 + 2i)},
+		{"complex128", rv.FieldByName("Complex128"), "(1.5-2i)", complex128(1.5 - 2i)},
+		{"uintptr", rv.FieldByName("Uintptr"), "1024", uintptr(1024)},
+		{"time.Duration", rv.FieldByName("Timeout"), "1m30s", 90 * time.Second},
+		{"ptr time.Duration", rv.FieldByName("PtrTimeout"), "2s", ptr.Ptr(2 * time.Second)},
+		{"ptr ptr time.Duration", rv.FieldByName("PtrPtrTimeout"), "3s", ptr.Ptr(ptr.Ptr(3 * time.Second))},
+		{"time.Time", rv.FieldByName("Time"), "2024-05-24T10:30:00.0000005Z", tm},
+		{"ptr time.Time", rv.FieldByName("PtrTime"), "2024-05-24T10:30:00.0000005Z", &tm},
+		{"[]time.Duration", rv.FieldByName("Timeouts"), "1s,1h0m0s", []time.Duration{time.Second, time.Hour}},
+		{"ptr ptr TextMarshaler", rv.FieldByName("PtrPtrDur"), "2s", ptr.Ptr(&d)},
+		{"direct complex64", ptr.Complex64(0), "(0+1i)", ptr.Complex64(1i)},
+		{"direct complex128", ptr.Complex128(0), "(1+0i)", ptr.Complex128(1)},
+		{"direct uintptr", ptr.Uintptr(0), "1", ptr.Uintptr(1)},
+		{"direct time.Duration", ptr.Ptr(time.Duration(0)), "1ms", ptr.Ptr(time.Millisecond)},
+		{"direct time.Time", ptr.Ptr(time.Time{}), "2024-05-24T10:30:00.0000005Z", &tm},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := UnmarshalText(c.v, []byte(c.text))
+			NewWithT(t).Expect(err).To(BeNil())
+
+			if rv, ok := c.v.(reflect.Value); ok {
+				NewWithT(t).Expect(rv.Interface()).To(Equal(c.expect))
+			} else {
+				NewWithT(t).Expect(c.v).To(Equal(c.expect))
+			}
+
+			text, err := MarshalText(c.v)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(text)).To(Equal(c.text))
+		})
+	}
+
+	t.Run("time layout", func(t *testing.T) {
+		c := NewTextCodec(WithTimeLayout("2006-01-02"))
+
+		text, err := c.MarshalText(tm)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("2024-05-24"))
+
+		parsed := time.Time{}
+		NewWithT(t).Expect(c.UnmarshalText(&parsed, text)).To(BeNil())
+		NewWithT(t).Expect(parsed).To(Equal(time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("time layout tag", func(t *testing.T) {
+		c, err := DefaultTextCodec.ForField(`text:"bytes=hex,layout=Mon, 02 Jan 2006"`)
+		NewWithT(t).Expect(err).To(BeNil())
+
+		text, err := c.MarshalText(tm)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("Fri, 24 May 2024"))
+
+		text, err = c.MarshalText([]byte{1})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(string(text)).To(Equal("01"))
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		err := UnmarshalText(ptr.Complex64(0), []byte("(1e39+0i)"))
+		NewWithT(t).Expect(errors.Is(err, strconv.ErrRange)).To(BeTrue())
+	})
+}
@@ -0,0 +1,232 @@
+// Package texttest checks values round trip through encoding.MarshalText and encoding.UnmarshalText,
+// so TextMarshaler implementations and codecs registered by encoding.RegisterTextCodec could be tested in one line.
+//
+//	func TestLevel(t *testing.T) {
+//		texttest.RoundTrip(t, LevelInfo)
+//	}
+//
+//	func FuzzLevel(f *testing.F) {
+//		texttest.Fuzz[Level](f, texttest.WithSeeds(LevelDebug, LevelInfo))
+//	}
+package texttest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/utilsgo/x/encoding"
+)
+
+type options struct {
+	codec *encoding.TextCodec
+	equal func(expect any, actual any) bool
+	seed  int64
+	seeds []any
+}
+
+type Option func(o *options)
+
+// WithTextCodec sets TextCodec to check. default encoding.DefaultTextCodec
+func WithTextCodec(c *encoding.TextCodec) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+// WithEqual sets how decoded values compared with expected ones. default reflect.DeepEqual
+func WithEqual(equal func(expect any, actual any) bool) Option {
+	return func(o *options) {
+		o.equal = equal
+	}
+}
+
+// WithSeed sets seed of rand for generators. default 1
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// WithSeeds sets values of which texts are added to corpus of Fuzz, seeds should be of the fuzzed type
+func WithSeeds[T any](seeds ...T) Option {
+	return func(o *options) {
+		for _, seed := range seeds {
+			o.seeds = append(o.seeds, seed)
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		codec: encoding.DefaultTextCodec,
+		equal: reflect.DeepEqual,
+		seed:  1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// holder has fields of T in each pointer depth, which are converted through reflect.Value path
+type holder[T any] struct {
+	V  T
+	P  *T
+	PP **T
+}
+
+// RoundTrip checks v is encoded to same text and decoded back to v through both
+// the direct path, like MarshalText(v) and UnmarshalText(&x, text),
+// and the reflect.Value path of struct fields of T, *T and **T.
+// nil *T and **T should be encoded to same text through both paths,
+// and when it is the null text, decoded back to nil.
+// Reports failures to t and returns false when any.
+func RoundTrip[T any](t testing.TB, v T, opts ...Option) bool {
+	t.Helper()
+	return roundTrip(t, newOptions(opts), v, "")
+}
+
+// RoundTripGenerated checks n values from gen like RoundTrip.
+// gen is called with rand seeded by WithSeed and index of value, so failures are reproducible.
+func RoundTripGenerated[T any](t testing.TB, n int, gen func(r *rand.Rand, i int) T, opts ...Option) bool {
+	t.Helper()
+
+	o := newOptions(opts)
+	r := rand.New(rand.NewSource(o.seed))
+
+	ok := true
+	for i := 0; i < n; i++ {
+		v := gen(r, i)
+		if !roundTrip(t, o, v, fmt.Sprintf("generated #%d (seed %d): ", i, o.seed)) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Fuzz fuzzes text of T with texts of WithSeeds as corpus, texts could be added by f.Add([]byte) too.
+// Each text decoded without error should round trip like RoundTrip by the TextCodec and equal of options,
+// and no text should panic.
+func Fuzz[T any](f *testing.F, opts ...Option) {
+	f.Helper()
+
+	o := newOptions(opts)
+	for _, s := range o.seeds {
+		seed, ok := s.(T)
+		if !ok {
+			f.Fatalf("seed %#v is not %T", s, seed)
+		}
+		text, err := o.codec.MarshalText(seed)
+		if err != nil {
+			f.Fatalf("marshal seed %#v failed: %s", seed, err)
+		}
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v T
+		if err := o.codec.UnmarshalText(&v, data); err != nil {
+			return
+		}
+		roundTrip(t, o, v, fmt.Sprintf("decoded from %q: ", data))
+	})
+}
+
+func roundTrip[T any](t testing.TB, o *options, v T, name string) bool {
+	t.Helper()
+
+	c := o.codec
+	ok := true
+
+	fail := func(format string, args ...any) {
+		t.Helper()
+		ok = false
+		t.Errorf(name+format, args...)
+	}
+
+	// direct path
+	text, err := c.MarshalText(v)
+	if err != nil {
+		fail("MarshalText(%#v) failed: %s", v, err)
+		return false
+	}
+
+	if ptrText, err := c.MarshalText(&v); err != nil || string(ptrText) != string(text) {
+		fail("MarshalText(&%#v) = %q, %v, but MarshalText of value = %q", v, ptrText, err, text)
+	}
+
+	var x T
+	if err := c.UnmarshalText(&x, text); err != nil {
+		fail("UnmarshalText(%q) failed: %s", text, err)
+	} else if !o.equal(v, x) {
+		fail("UnmarshalText(%q) = %#v, but expect %#v", text, x, v)
+	}
+
+	// reflect.Value path
+	p := &v
+	rv := reflect.ValueOf(&holder[T]{V: v, P: p, PP: &p}).Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		fieldText, err := c.MarshalText(rv.Field(i))
+		if err != nil || string(fieldText) != string(text) {
+			fail("MarshalText of field %s = %q, %v, but expect %q", rv.Type().Field(i).Name, fieldText, err, text)
+		}
+	}
+
+	decoded := holder[T]{}
+	rv = reflect.ValueOf(&decoded).Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		if err := c.UnmarshalText(rv.Field(i), text); err != nil {
+			fail("UnmarshalText(%q) to field %s failed: %s", text, rv.Type().Field(i).Name, err)
+		}
+	}
+
+	if !o.equal(v, decoded.V) {
+		fail("UnmarshalText(%q) to field V = %#v, but expect %#v", text, decoded.V, v)
+	}
+	if decoded.P == nil || !o.equal(v, *decoded.P) {
+		fail("UnmarshalText(%q) to field P = %#v, but expect &%#v", text, decoded.P, v)
+	}
+	if decoded.PP == nil || *decoded.PP == nil || !o.equal(v, **decoded.PP) {
+		fail("UnmarshalText(%q) to field PP = %#v, but expect &&%#v", text, decoded.PP, v)
+	}
+
+	// nil pointers
+	var nilPtr *T
+
+	nilText, err := c.MarshalText(nilPtr)
+	if err != nil {
+		fail("MarshalText of nil failed: %s", err)
+		return ok
+	}
+
+	rv = reflect.ValueOf(&holder[T]{}).Elem()
+
+	for _, field := range []string{"P", "PP"} {
+		fieldText, err := c.MarshalText(rv.FieldByName(field))
+		if err != nil || string(fieldText) != string(nilText) {
+			fail("MarshalText of nil field %s = %q, %v, but expect %q", field, fieldText, err, nilText)
+		}
+	}
+
+	if c.IsNullText(nilText) {
+		direct := &v
+		if err := c.UnmarshalText(&direct, nilText); err != nil || direct != nil {
+			fail("UnmarshalText(%q) = %#v, %v, but expect nil", nilText, direct, err)
+		}
+
+		rv = reflect.ValueOf(&holder[T]{P: p, PP: &p}).Elem()
+
+		for _, field := range []string{"P", "PP"} {
+			fv := rv.FieldByName(field)
+			if err := c.UnmarshalText(fv, nilText); err != nil || !fv.IsNil() {
+				fail("UnmarshalText(%q) to field %s = %#v, %v, but expect nil", nilText, field, fv.Interface(), err)
+			}
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,130 @@
+package texttest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/encoding"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+var levelNames = []string{"debug", "info"}
+
+func (l Level) MarshalText() ([]byte, error) {
+	if l < 0 || int(l) >= len(levelNames) {
+		return nil, fmt.Errorf("invalid level %d", l)
+	}
+	return []byte(levelNames[l]), nil
+}
+
+func (l *Level) UnmarshalText(data []byte) error {
+	for i, name := range levelNames {
+		if name == string(data) {
+			*l = Level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid level %q", data)
+}
+
+// Truncated loses text longer than 3 bytes
+type Truncated string
+
+func (s Truncated) MarshalText() ([]byte, error) {
+	if len(s) > 3 {
+		return []byte(s[:3]), nil
+	}
+	return []byte(s), nil
+}
+
+func (s *Truncated) UnmarshalText(data []byte) error {
+	*s = Truncated(data)
+	return nil
+}
+
+type NamedString string
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Run("passed", func(t *testing.T) {
+		RoundTrip(t, LevelInfo)
+		RoundTrip(t, 42)
+		RoundTrip(t, NamedString("named"))
+		RoundTrip(t, 3*time.Second)
+		RoundTrip(t, []string{"a", "b,c"})
+		RoundTrip(t, 42, WithTextCodec(encoding.NewTextCodec(encoding.WithNullPolicy(encoding.NullAsText))))
+		RoundTrip(t, 42, WithTextCodec(encoding.NewTextCodec(encoding.WithIntBase(16))))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r := &recorder{TB: t}
+		NewWithT(t).Expect(RoundTrip[Truncated](r, "long")).To(BeFalse())
+		NewWithT(t).Expect(r.errors).NotTo(BeEmpty())
+		NewWithT(t).Expect(r.errors[0]).To(ContainSubstring(`UnmarshalText("lon")`))
+
+		r = &recorder{TB: t}
+		NewWithT(t).Expect(RoundTrip(r, Level(5))).To(BeFalse())
+		NewWithT(t).Expect(r.errors).To(HaveLen(1))
+	})
+
+	t.Run("equal", func(t *testing.T) {
+		r := &recorder{TB: t}
+		NewWithT(t).Expect(RoundTrip[Truncated](r, "long", WithEqual(func(expect any, actual any) bool {
+			return strings.HasPrefix(string(expect.(Truncated)), string(actual.(Truncated)))
+		}))).To(BeTrue())
+	})
+}
+
+func TestRoundTripGenerated(t *testing.T) {
+	RoundTripGenerated(t, 100, func(r *rand.Rand, i int) int64 {
+		return r.Int63() - r.Int63()
+	})
+
+	r := &recorder{TB: t}
+	NewWithT(t).Expect(RoundTripGenerated(r, 10, func(r *rand.Rand, i int) Truncated {
+		return Truncated(strings.Repeat("x", i))
+	}, WithSeed(2))).To(BeFalse())
+	NewWithT(t).Expect(r.errors[0]).To(HavePrefix("generated #4 (seed 2): "))
+}
+
+func FuzzLevel(f *testing.F) {
+	Fuzz[Level](f, WithSeeds(LevelDebug, LevelInfo))
+}
+
+func FuzzDuration(f *testing.F) {
+	Fuzz[time.Duration](f, WithSeeds(time.Second, -time.Hour))
+}
+
+func FuzzFloat(f *testing.F) {
+	f.Add([]byte("1e3"))
+
+	Fuzz[float64](f,
+		WithSeeds(1.5, math.Inf(-1), math.NaN()),
+		WithTextCodec(encoding.NewTextCodec(encoding.WithNullText("null"))),
+		WithEqual(func(expect any, actual any) bool {
+			x, y := expect.(float64), actual.(float64)
+			return x == y || (math.IsNaN(x) && math.IsNaN(y))
+		}),
+	)
+}
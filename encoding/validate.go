@@ -0,0 +1,544 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	pkgerrors "github.com/pkg/errors"
+	reflectx "github.com/utilsgo/x/reflect"
+	"github.com/utilsgo/x/types"
+)
+
+// TagValidate is the struct tag for validation rules of field.
+//
+//	Port int    `validate:"required,min=1,max=65535"`
+//	Mode string `validate:"omitempty,oneof=dev|prod"`
+const TagValidate = "validate"
+
+// ValidationRules of field, parsed from the `validate` tag.
+// Options are comma separated:
+//
+//	required: value must not be empty
+//	omitempty: other rules are skipped for empty values
+//	min, max: bound of number, or bound of length for string, slice, array and map.
+//	          bound of number is decoded as the field type, like `min=1s` for time.Duration
+//	oneof: text of value must be one of values separated by '|'
+//	pattern: text of value must match the regexp, must be the last option as pattern may contain ','
+//
+// Rules are checked on empty values too, like `min=1` breaks by 0, unless omitempty.
+// Rules except required are always skipped for nil pointers, which have no value.
+// For list values, oneof and pattern are checked on each element.
+type ValidationRules struct {
+	Required  bool
+	OmitEmpty bool
+	Min       string
+	Max       string
+	OneOf     []string
+	Pattern   string
+
+	pattern *regexp.Regexp
+}
+
+// ParseValidationRules parses value of the `validate` tag
+func ParseValidationRules(value string) (*ValidationRules, error) {
+	r := &ValidationRules{}
+
+	for value != "" {
+		var option string
+		option, value, _ = strings.Cut(value, ",")
+		key, val, _ := strings.Cut(option, "=")
+
+		switch key {
+		case "required":
+			r.Required = true
+		case "omitempty":
+			r.OmitEmpty = true
+		case "min":
+			r.Min = val
+		case "max":
+			r.Max = val
+		case "oneof":
+			r.OneOf = strings.Split(val, "|")
+		case "pattern":
+			if value != "" {
+				val, value = val+","+value, ""
+			}
+			p, err := regexp.Compile(val)
+			if err != nil {
+				return nil, pkgerrors.Wrapf(err, "invalid pattern %q", val)
+			}
+			r.Pattern = val
+			r.pattern = p
+		default:
+			return nil, pkgerrors.Errorf("unknown validate tag option %q", option)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *ValidationRules) String() string {
+	options := make([]string, 0, 6)
+	if r.Required {
+		options = append(options, "required")
+	}
+	if r.OmitEmpty {
+		options = append(options, "omitempty")
+	}
+	if r.Min != "" {
+		options = append(options, "min="+r.Min)
+	}
+	if r.Max != "" {
+		options = append(options, "max="+r.Max)
+	}
+	if len(r.OneOf) > 0 {
+		options = append(options, "oneof="+strings.Join(r.OneOf, "|"))
+	}
+	if r.Pattern != "" {
+		options = append(options, "pattern="+r.Pattern)
+	}
+	return strings.Join(options, ",")
+}
+
+// checkKind checks whether rules could be applied to values of kind
+func (r *ValidationRules) checkKind(kind reflect.Kind) error {
+	if r.Min == "" && r.Max == "" {
+		return nil
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return nil
+	}
+	return pkgerrors.Errorf("min or max is not supported for %s", kind)
+}
+
+// FieldValidation is the validation rules of field at Path
+type FieldValidation struct {
+	Path  string
+	Type  types.Type
+	Rules *ValidationRules
+}
+
+// ValidationRulesOf returns validation rules of all fields of struct typ, fields are named by tag.
+// typ could be types.RType or types.TType, so rules could be read from source code for docs.
+func ValidationRulesOf(typ types.Type, tag string) ([]*FieldValidation, error) {
+	typ = types.Deref(typ)
+	if typ.Kind() != reflect.Struct {
+		return nil, pkgerrors.Errorf("validation rules need struct type, but got %s", typ)
+	}
+	return validationRulesOf(typ, tag, "")
+}
+
+func validationRulesOf(typ types.Type, tag string, prefix string) (list []*FieldValidation, err error) {
+	types.EachField(typ, tag, func(field types.StructField, name string, omitempty bool) bool {
+		// EachField continues after embedded structs stopped
+		if err != nil {
+			return false
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if value, ok := field.Tag().Lookup(TagValidate); ok {
+			r, e := ParseValidationRules(value)
+			if e != nil {
+				err = pkgerrors.Wrapf(e, "field %s", name)
+				return false
+			}
+			if e := r.checkKind(types.Deref(field.Type()).Kind()); e != nil {
+				err = pkgerrors.Wrapf(e, "field %s", name)
+				return false
+			}
+			list = append(list, &FieldValidation{Path: name, Type: field.Type(), Rules: r})
+		}
+
+		if isNestedStructType(field.Type()) {
+			l, e := validationRulesOf(types.Deref(field.Type()), tag, name)
+			if e != nil {
+				err = e
+				return false
+			}
+			list = append(list, l...)
+		}
+
+		return true
+	})
+	return
+}
+
+// isNestedStructType is isNestedStruct of DefaultTextCodec for types.Type.
+// methods are matched by name, as types.TType could not be checked by reflect.Type.
+func isNestedStructType(typ types.Type) bool {
+	if t, ok := typ.(*types.RType); ok {
+		return DefaultTextCodec.isNestedStruct(t.Type)
+	}
+
+	typ = types.Deref(typ)
+	if typ.Kind() != reflect.Struct || types.FullTypeName(typ) == "time.Time" {
+		return false
+	}
+
+	ptrType := types.PtrTo(typ)
+	for _, name := range []string{"UnmarshalText", "UnmarshalBinary"} {
+		if _, ok := ptrType.MethodByName(name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidationFieldError describes field which breaks Rule
+type ValidationFieldError struct {
+	Path string
+	Rule string
+	Err  error
+}
+
+func (e *ValidationFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *ValidationFieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError aggregates all fields which break their rules
+type ValidationError struct {
+	Fields []*ValidationFieldError
+}
+
+func (e *ValidationError) Error() string {
+	buf := bytes.NewBufferString("validate failed: ")
+	for i, f := range e.Fields {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(f.Error())
+	}
+	return buf.String()
+}
+
+// Validate checks fields of struct v by the `validate` tag, fields are named by tag like decoding.
+// Fields under nil ptr structs have no value, only their required rules are checked.
+// Broken rules of all fields are returned together as *ValidationError,
+// invalid rules and nil v are returned directly.
+func Validate(v any, tag string) error {
+	rv, ok := indirectStruct(v, false)
+	if !ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && reflectx.Deref(rv.Type()).Kind() == reflect.Struct {
+			return pkgerrors.Errorf("validate need non-nil struct value, but got nil %T", v)
+		}
+		return pkgerrors.Errorf("validate need struct value, but got %T", v)
+	}
+
+	fields, err := fieldValidationsFor(rv.Type(), tag)
+	if err != nil {
+		return err
+	}
+
+	validationErr := &ValidationError{}
+	// index of fields broke rules, fields under them are skipped
+	broken := make([][]int, 0)
+
+	for _, f := range fields {
+		if hasIndexPrefix(f.index, broken) {
+			continue
+		}
+
+		rule, err := f.validate(rv)
+		if err != nil {
+			if _, ok := err.(*ruleError); !ok {
+				return pkgerrors.Wrapf(err, "field %s", f.path)
+			}
+			validationErr.Fields = append(validationErr.Fields, &ValidationFieldError{
+				Path: f.path,
+				Rule: rule,
+				Err:  err,
+			})
+			broken = append(broken, f.index)
+		}
+	}
+
+	if len(validationErr.Fields) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// fieldValidation is rules of field at index of the root struct
+type fieldValidation struct {
+	path  string
+	index []int
+	rules *ValidationRules
+	tc    *TextCodec
+}
+
+func (f *fieldValidation) validate(rv reflect.Value) (string, error) {
+	fv, ok := fieldByIndex(rv, f.index, false)
+	if !ok {
+		// field under nil ptr struct
+		if f.rules.Required {
+			return "required", newRuleError("required")
+		}
+		return "", nil
+	}
+	return f.rules.validate(f.tc, fv)
+}
+
+type fieldValidationsKey struct {
+	typ reflect.Type
+	tag string
+}
+
+type fieldValidations struct {
+	fields []*fieldValidation
+	err    error
+}
+
+// fieldValidationsFor returns rules of fields of struct typ, which is cached per type and tag like TypeCodec
+func fieldValidationsFor(typ reflect.Type, tag string) ([]*fieldValidation, error) {
+	cache := DefaultTextCodec.typeCodecCache()
+	key := fieldValidationsKey{typ: typ, tag: tag}
+
+	if v, ok := cache.validations.Load(key); ok {
+		fv := v.(*fieldValidations)
+		return fv.fields, fv.err
+	}
+
+	fields, err := fieldValidationsOf(typ, tag, "", nil)
+	cache.validations.Store(key, &fieldValidations{fields: fields, err: err})
+	return fields, err
+}
+
+// fieldValidationsOf returns rules of fields of struct typ, which is at index of the root struct.
+// rules of nested struct field come before rules of its fields.
+func fieldValidationsOf(typ reflect.Type, tag string, prefix string, index []int) (list []*fieldValidation, err error) {
+	walkStructFields(typ, tag, index, func(field types.StructField, name string, omitempty bool, fieldIndex []int) bool {
+		fieldType := field.(*types.RStructField).StructField.Type
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if value, ok := field.Tag().Lookup(TagValidate); ok {
+			f, e := newFieldValidation(field, fieldType, value)
+			if e != nil {
+				err = pkgerrors.Wrapf(e, "field %s", name)
+				return false
+			}
+			f.path = name
+			f.index = fieldIndex
+			list = append(list, f)
+		}
+
+		if DefaultTextCodec.isNestedStruct(fieldType) {
+			l, e := fieldValidationsOf(reflectx.Deref(fieldType), tag, name, fieldIndex)
+			if e != nil {
+				err = e
+				return false
+			}
+			list = append(list, l...)
+		}
+
+		return true
+	})
+	return
+}
+
+func newFieldValidation(field types.StructField, fieldType reflect.Type, value string) (*fieldValidation, error) {
+	r, err := ParseValidationRules(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkKind(reflectx.Deref(fieldType).Kind()); err != nil {
+		return nil, err
+	}
+
+	tc, err := DefaultTextCodec.ForField(field.Tag())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkBounds(tc, reflectx.Deref(fieldType)); err != nil {
+		return nil, err
+	}
+
+	return &fieldValidation{rules: r, tc: tc}, nil
+}
+
+// hasIndexPrefix reports whether index is under any of prefixes
+func hasIndexPrefix(index []int, prefixes [][]int) bool {
+	for _, prefix := range prefixes {
+		if len(prefix) < len(index) && indexEqual(index[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexEqual(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// ruleError is the error of broken rule, others are errors of invalid rules
+type ruleError struct {
+	msg string
+}
+
+func (e *ruleError) Error() string {
+	return e.msg
+}
+
+func newRuleError(format string, args ...any) error {
+	return &ruleError{msg: fmt.Sprintf(format, args...)}
+}
+
+// validate returns name of the broken rule with error
+func (r *ValidationRules) validate(tc *TextCodec, rv reflect.Value) (string, error) {
+	if reflectx.IsEmptyValue(rv) {
+		if r.Required {
+			return "required", newRuleError("required")
+		}
+		if r.OmitEmpty {
+			return "", nil
+		}
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", nil
+		}
+		rv = rv.Elem()
+	}
+
+	if r.Min != "" {
+		if c, err := r.compare(tc, rv, r.Min); err != nil {
+			return "min", err
+		} else if c < 0 {
+			return "min", newRuleError("%s must be >= %s", r.measure(rv), r.Min)
+		}
+	}
+
+	if r.Max != "" {
+		if c, err := r.compare(tc, rv, r.Max); err != nil {
+			return "max", err
+		} else if c > 0 {
+			return "max", newRuleError("%s must be <= %s", r.measure(rv), r.Max)
+		}
+	}
+
+	if len(r.OneOf) == 0 && r.pattern == nil {
+		return "", nil
+	}
+
+	values := []reflect.Value{rv}
+	if isTextList(rv.Type()) {
+		values = make([]reflect.Value, rv.Len())
+		for i := range values {
+			values[i] = rv.Index(i)
+		}
+	}
+
+	for _, v := range values {
+		text, err := tc.MarshalText(v)
+		if err != nil {
+			return "", err
+		}
+
+		if len(r.OneOf) > 0 && !r.isOneOf(string(text)) {
+			return "oneof", newRuleError("%q is not one of %s", text, strings.Join(r.OneOf, "|"))
+		}
+
+		if r.pattern != nil && !r.pattern.Match(text) {
+			return "pattern", newRuleError("%q does not match pattern %s", text, r.Pattern)
+		}
+	}
+
+	return "", nil
+}
+
+func (r *ValidationRules) isOneOf(text string) bool {
+	for _, v := range r.OneOf {
+		if v == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ValidationRules) measure(rv reflect.Value) string {
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return "length"
+	}
+	return "value"
+}
+
+// checkBounds checks min and max could be decoded as bounds of values of typ,
+// so invalid rules are found whatever the value is.
+func (r *ValidationRules) checkBounds(tc *TextCodec, typ reflect.Type) error {
+	for _, bound := range []string{r.Min, r.Max} {
+		if bound == "" {
+			continue
+		}
+		if _, err := r.compare(tc, reflect.Zero(typ), bound); err != nil {
+			return pkgerrors.Wrapf(err, "invalid bound %q", bound)
+		}
+	}
+	return nil
+}
+
+// compare returns -1, 0 or 1 when the number or length of rv is less than, equal to or greater than bound
+func (r *ValidationRules) compare(tc *TextCodec, rv reflect.Value, bound string) (int, error) {
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		var n int
+		if err := tc.UnmarshalText(&n, []byte(bound)); err != nil {
+			return 0, err
+		}
+		l := rv.Len()
+		if rv.Kind() == reflect.String {
+			l = utf8.RuneCountInString(rv.String())
+		}
+		return compareOrdered(l, n), nil
+	}
+
+	b := reflect.New(rv.Type())
+	if err := tc.UnmarshalText(b, []byte(bound)); err != nil {
+		return 0, err
+	}
+	b = b.Elem()
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(rv.Int(), b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(rv.Uint(), b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(rv.Float(), b.Float()), nil
+	}
+	return 0, pkgerrors.Errorf("min or max is not supported for %s", rv.Kind())
+}
+
+func compareOrdered[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
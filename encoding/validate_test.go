@@ -0,0 +1,229 @@
+package encoding
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/utilsgo/x/ptr"
+	"github.com/utilsgo/x/types"
+)
+
+type ValidateDB struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"min=1,max=65535"`
+}
+
+type ValidateMeta struct {
+	Owner string `json:"owner" validate:"omitempty,pattern=^[a-z]+$"`
+}
+
+type ValidateConfig struct {
+	ValidateMeta
+	Name    string        `json:"name" validate:"required,min=2,max=8"`
+	Mode    string        `json:"mode" validate:"omitempty,oneof=dev|prod"`
+	Timeout time.Duration `json:"timeout" validate:"min=1s,max=1m"`
+	Ratio   *float64      `json:"ratio" validate:"max=1"`
+	Tags    []string      `json:"tags" validate:"max=2,oneof=a|b|c"`
+	DB      ValidateDB    `json:"db"`
+	Replica *ValidateDB   `json:"replica"`
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := ValidateConfig{
+			ValidateMeta: ValidateMeta{Owner: "ops"},
+			Name:         "srv",
+			Mode:         "dev",
+			Timeout:      time.Second,
+			Ratio:        ptr.Float64(0.5),
+			Tags:         []string{"a", "c"},
+			DB:           ValidateDB{Host: "localhost", Port: 5432},
+			Replica:      &ValidateDB{Host: "replica", Port: 5432},
+		}
+		NewWithT(t).Expect(Validate(&c, "json")).To(BeNil())
+	})
+
+	t.Run("required under nil ptr", func(t *testing.T) {
+		v := struct {
+			*ValidateDB
+			Replica *ValidateDB `json:"replica"`
+			Backup  *ValidateDB `json:"backup" validate:"required"`
+		}{}
+
+		validationErr := &ValidationError{}
+		NewWithT(t).Expect(errors.As(Validate(&v, "json"), &validationErr)).To(BeTrue())
+		NewWithT(t).Expect(validationErr.Error()).To(Equal("validate failed: host: required; replica.host: required; backup: required"))
+	})
+
+	t.Run("cached rules", func(t *testing.T) {
+		fields, err := fieldValidationsFor(reflect.TypeOf(ValidateConfig{}), "json")
+		NewWithT(t).Expect(err).To(BeNil())
+		cached, _ := fieldValidationsFor(reflect.TypeOf(ValidateConfig{}), "json")
+		NewWithT(t).Expect(cached[0]).To(BeIdenticalTo(fields[0]))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		err := Validate((*ValidateConfig)(nil), "json")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(errors.As(err, new(*ValidationError))).To(BeFalse())
+	})
+
+	t.Run("empty values skip rules only when omitempty", func(t *testing.T) {
+		c := ValidateConfig{
+			Name:    "srv",
+			Timeout: time.Second,
+			DB:      ValidateDB{Host: "localhost", Port: 5432},
+			Replica: &ValidateDB{Host: "replica", Port: 5432},
+		}
+		NewWithT(t).Expect(Validate(c, "json")).To(BeNil())
+
+		c.DB.Port = 0
+		c.Timeout = 0
+
+		validationErr := &ValidationError{}
+		NewWithT(t).Expect(errors.As(Validate(c, "json"), &validationErr)).To(BeTrue())
+		NewWithT(t).Expect(validationErr.Fields).To(HaveLen(2))
+		NewWithT(t).Expect(validationErr.Fields[0].Path).To(Equal("timeout"))
+		NewWithT(t).Expect(validationErr.Fields[1].Error()).To(Equal("db.port: value must be >= 1"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := ValidateConfig{
+			ValidateMeta: ValidateMeta{Owner: "Ops"},
+			Name:         "s",
+			Mode:         "test",
+			Timeout:      time.Hour,
+			Ratio:        ptr.Float64(2),
+			Tags:         []string{"a", "d"},
+			DB:           ValidateDB{Port: 70000},
+			Replica:      &ValidateDB{Host: "replica", Port: -1},
+		}
+
+		err := Validate(&c, "json")
+
+		validationErr := &ValidationError{}
+		NewWithT(t).Expect(errors.As(err, &validationErr)).To(BeTrue())
+
+		paths := make([]string, 0)
+		rules := make([]string, 0)
+		for _, f := range validationErr.Fields {
+			paths = append(paths, f.Path)
+			rules = append(rules, f.Rule)
+		}
+
+		NewWithT(t).Expect(paths).To(Equal([]string{
+			"owner", "name", "mode", "timeout", "ratio", "tags", "db.host", "db.port", "replica.port",
+		}))
+		NewWithT(t).Expect(rules).To(Equal([]string{
+			"pattern", "min", "oneof", "max", "max", "oneof", "required", "max", "min",
+		}))
+		NewWithT(t).Expect(validationErr.Fields[1].Error()).To(Equal("name: length must be >= 2"))
+		NewWithT(t).Expect(validationErr.Fields[2].Error()).To(Equal(`mode: "test" is not one of dev|prod`))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		NewWithT(t).Expect(Validate(&struct {
+			V bool `validate:"min=1"`
+		}{}, "json")).NotTo(BeNil())
+
+		NewWithT(t).Expect(Validate(&struct {
+			V int `validate:"min=x"`
+		}{V: 1}, "json")).NotTo(BeNil())
+
+		for _, v := range []any{
+			&struct {
+				V int `validate:"omitempty,min=x"`
+			}{},
+			&struct {
+				V *int `validate:"max=x"`
+			}{},
+		} {
+			err := Validate(v, "json")
+			NewWithT(t).Expect(err).NotTo(BeNil())
+			NewWithT(t).Expect(errors.As(err, new(*ValidationError))).To(BeFalse())
+		}
+
+		NewWithT(t).Expect(Validate(&struct {
+			V int `validate:"unknown"`
+		}{}, "json")).NotTo(BeNil())
+	})
+}
+
+func TestParseValidationRules(t *testing.T) {
+	r, err := ParseValidationRules("required,omitempty,min=1,oneof=a|b,pattern=^a{1,2}$")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(r.Required).To(BeTrue())
+	NewWithT(t).Expect(r.OmitEmpty).To(BeTrue())
+	NewWithT(t).Expect(r.Min).To(Equal("1"))
+	NewWithT(t).Expect(r.OneOf).To(Equal([]string{"a", "b"}))
+	NewWithT(t).Expect(r.Pattern).To(Equal("^a{1,2}$"))
+	NewWithT(t).Expect(r.String()).To(Equal("required,omitempty,min=1,oneof=a|b,pattern=^a{1,2}$"))
+
+	_, err = ParseValidationRules("pattern=(")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestValidationRulesOf(t *testing.T) {
+	t.Run("invalid rule of embedded field", func(t *testing.T) {
+		type Embedded struct {
+			V bool `validate:"min=1"`
+		}
+		_, err := ValidationRulesOf(types.FromRType(reflect.TypeOf(struct {
+			Embedded
+			W int `validate:"unknown"`
+		}{})), "json")
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("field V"))
+	})
+
+	expect := map[string]string{
+		"owner":        "omitempty,pattern=^[a-z]+$",
+		"name":         "required,min=2,max=8",
+		"mode":         "omitempty,oneof=dev|prod",
+		"timeout":      "min=1s,max=1m",
+		"ratio":        "max=1",
+		"tags":         "max=2,oneof=a|b|c",
+		"db.host":      "required",
+		"db.port":      "min=1,max=65535",
+		"replica.host": "required",
+		"replica.port": "min=1,max=65535",
+	}
+
+	for _, typ := range []types.Type{
+		types.FromRType(reflect.TypeOf(ValidateConfig{})),
+		ttypeOf(t, "testdata/config/config.go", "ValidateConfig"),
+	} {
+		t.Run(typ.String(), func(t *testing.T) {
+			list, err := ValidationRulesOf(typ, "json")
+			NewWithT(t).Expect(err).To(BeNil())
+
+			rules := map[string]string{}
+			for _, f := range list {
+				rules[f.Path] = f.Rules.String()
+			}
+			NewWithT(t).Expect(rules).To(Equal(expect))
+		})
+	}
+}
+
+// ttypeOf type-checks the source file and returns TType of name in it.
+// packages are not loaded, so it works without export data.
+func ttypeOf(t *testing.T, filename string, name string) types.Type {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, 0)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	conf := gotypes.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	return types.FromTType(pkg.Scope().Lookup(name).Type())
+}